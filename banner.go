@@ -0,0 +1,62 @@
+package console
+
+import (
+	"log/slog"
+)
+
+// Banner writes a multi-line banner, typically at application startup,
+// made of a divider titled with name and version, followed by one line
+// per attribute summarizing the configuration, and a closing divider.
+// It shares the handler's theme and NoColor setting, so it is rendered
+// consistently with the log records. Nothing is written if the handler
+// is disabled, or if its output is not a terminal, like when it's piped
+// or redirected to a file. With HandlerOptions.Plain, the title and the
+// attributes are logged as a record at info level instead, wherever the
+// output goes.
+func (h *Handler) Banner(name, version string, attrs ...slog.Attr) error {
+	if h.opts.Plain && !h.disabled() {
+		title := name
+		if version != "" {
+			title += " " + version
+		}
+		return h.logPlain(title, attrs)
+	}
+	if h.disabled() || !h.out.isTerminal() {
+		return nil
+	}
+	buf := h.pool.get()
+	enc, _, _ := h.rendering()
+	enc.writeBanner(buf, name, version, attrs, h.opts.width())
+	_, err := buf.WriteTo(h.out)
+	h.releaseBuffer(buf)
+	return err
+}
+
+// Banner writes a banner with the handler of logger, if it's a *Handler.
+// See Handler.Banner.
+func Banner(logger *slog.Logger, name, version string, attrs ...slog.Attr) {
+	if h, ok := logger.Handler().(*Handler); ok {
+		_ = h.Banner(name, version, attrs...)
+	}
+}
+
+func (e *encoder) writeBanner(buf *buffer, name, version string, attrs []slog.Attr, width int) {
+	title := name
+	if version != "" {
+		title += " " + version
+	}
+	e.writeDivider(buf, title, width)
+	var pad int
+	var pe encoder
+	e = e.withPad(&pad, &pe)
+	for _, a := range attrs {
+		mark := buf.Len()
+		buf.AppendString("  ")
+		if !e.writeAttrSep(buf, a, nil, false) {
+			*buf = (*buf)[:mark]
+			continue
+		}
+		e.NewLine(buf)
+	}
+	e.writeDivider(buf, "", width)
+}
@@ -0,0 +1,37 @@
+package console
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestBanner(t *testing.T) {
+	buf := buffer{}
+	enc := newEncoder(HandlerOptions{NoColor: true, KeyValueSeparator: "=", Theme: NewDefaultTheme()}, nil)
+	enc.writeBanner(&buf, "app", "v1.2.3", []slog.Attr{slog.String("env", "prod"), {}, slog.Int("port", 8080)}, 20)
+	AssertEqual(t, "──── app v1.2.3 ────\n  env=prod\n  port=8080\n"+strings.Repeat("─", 20)+"\n", buf.String())
+
+	// Not written to a pipe or a file
+	out := bytes.Buffer{}
+	h := NewHandler(&out, &HandlerOptions{NoColor: true, Width: 20})
+	Banner(slog.New(h), "app", "v1.2.3", slog.String("env", "prod"))
+	AssertEqual(t, "", out.String())
+
+	h = NewHandler(&out, &HandlerOptions{NoColor: true, Disabled: true})
+	AssertNoError(t, h.Banner("app", ""))
+	AssertEqual(t, "", out.String())
+}
+
+func TestBanner_Plain(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{Plain: true, TimeFormat: "-"})
+	AssertNoError(t, h.Banner("app", "v1.2.3", slog.String("env", "prod"), slog.Int("port", 8080)))
+	AssertEqual(t, "- INF app v1.2.3 env=prod port=8080\n", buf.String())
+
+	buf.Reset()
+	h = NewHandler(&buf, &HandlerOptions{Plain: true, Level: slog.LevelWarn})
+	AssertNoError(t, h.Banner("app", ""))
+	AssertEqual(t, "", buf.String())
+}
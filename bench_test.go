@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -21,7 +22,7 @@ var handlers = []struct {
 	hdl  slog.Handler
 }{
 	{"dummy", &DummyHandler{}},
-	{"console", NewHandler(io.Discard, &HandlerOptions{Level: slog.LevelDebug, AddSource: false})},
+	{"console", NewHandler(nopWriter, &HandlerOptions{Level: slog.LevelDebug, AddSource: false})},
 	{"std-text", slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: false})},
 	{"std-json", slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: false})},
 }
@@ -77,3 +78,46 @@ func BenchmarkLoggers(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkHandlerOptions(b *testing.B) {
+	ctx := context.Background()
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+
+	for _, tc := range []struct {
+		name  string
+		opts  *HandlerOptions
+		level slog.Level
+	}{
+		{"default", &HandlerOptions{}, slog.LevelInfo},
+		{"no-color", &HandlerOptions{NoColor: true}, slog.LevelInfo},
+		{"source", &HandlerOptions{AddSource: true}, slog.LevelInfo},
+		{"level-offset", &HandlerOptions{}, slog.LevelInfo + 2},
+		{"single-goroutine", &HandlerOptions{Pool: PoolSingleGoroutine}, slog.LevelInfo},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			rec := slog.NewRecord(time.Now(), tc.level, "hello", pcs[0])
+			rec.AddAttrs(attrs...)
+			h := NewHandler(nopWriter, tc.opts).WithGroup("test").WithAttrs(attrs)
+			// Warm-up
+			_ = h.Handle(ctx, rec)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = h.Handle(ctx, rec)
+			}
+		})
+	}
+}
+
+func BenchmarkHandler_Discard(b *testing.B) {
+	ctx := context.Background()
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(attrs...)
+	h := NewHandler(io.Discard, nil).WithAttrs(attrs)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h.Handle(ctx, rec)
+	}
+}
@@ -80,7 +80,7 @@ func (b *buffer) AppendByte(byt byte) {
 }
 
 func (b *buffer) AppendTime(t time.Time, format string) {
-	*b = t.AppendFormat(*b, format)
+	*b = appendTime(*b, t, format)
 }
 
 func (b *buffer) AppendInt(i int64) {
@@ -102,3 +102,70 @@ func (b *buffer) AppendBool(i bool) {
 func (b *buffer) AppendDuration(d time.Duration) {
 	*b = appendDuration(*b, d)
 }
+
+// Buffer is a byte buffer holding a log line being built
+// with an Encoder.
+// The zero value is an empty buffer ready to use.
+type Buffer buffer
+
+// Bytes returns the content of the buffer. The slice is only valid
+// until the next modification of the buffer.
+func (b *Buffer) Bytes() []byte {
+	return (*buffer)(b).Bytes()
+}
+
+// String returns the content of the buffer as a string.
+func (b *Buffer) String() string {
+	return (*buffer)(b).String()
+}
+
+// Len returns the number of bytes in the buffer.
+func (b *Buffer) Len() int {
+	return (*buffer)(b).Len()
+}
+
+// Reset empties the buffer, retaining its capacity.
+func (b *Buffer) Reset() {
+	(*buffer)(b).Reset()
+}
+
+// Write implements io.Writer. It never fails.
+func (b *Buffer) Write(p []byte) (int, error) {
+	(*buffer)(b).Append(p)
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer. It never fails.
+func (b *Buffer) WriteString(s string) (int, error) {
+	(*buffer)(b).AppendString(s)
+	return len(s), nil
+}
+
+// WriteByte appends c to the buffer. It never fails.
+func (b *Buffer) WriteByte(c byte) error {
+	(*buffer)(b).AppendByte(c)
+	return nil
+}
+
+// AppendInt appends the decimal representation of i to the buffer.
+func (b *Buffer) AppendInt(i int64) {
+	(*buffer)(b).AppendInt(i)
+}
+
+// AppendTime appends t formatted with layout to the buffer. The layout
+// may also be one of the TimeFormatUnix pseudo layouts.
+func (b *Buffer) AppendTime(t time.Time, layout string) {
+	(*buffer)(b).AppendTime(t, layout)
+}
+
+// AppendDuration appends the representation of d to the buffer,
+// like time.Duration.String.
+func (b *Buffer) AppendDuration(d time.Duration) {
+	(*buffer)(b).AppendDuration(d)
+}
+
+// WriteTo writes the content of the buffer to dst, then
+// empties it if the write succeeded.
+func (b *Buffer) WriteTo(dst io.Writer) (int64, error) {
+	return (*buffer)(b).WriteTo(dst)
+}
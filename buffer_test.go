@@ -133,3 +133,29 @@ func BenchmarkBuffer(b *testing.B) {
 		}
 	})
 }
+
+func TestBuffer_Public(t *testing.T) {
+	b := Buffer{}
+	_, _ = b.WriteString("foo")
+	_ = b.WriteByte(' ')
+	_, _ = b.Write([]byte("bar"))
+	AssertEqual(t, "foo bar", b.String())
+	AssertEqual(t, 7, b.Len())
+
+	dest := bytes.Buffer{}
+	n, err := b.WriteTo(&dest)
+	AssertNoError(t, err)
+	AssertEqual(t, int64(7), n)
+	AssertEqual(t, "foo bar", dest.String())
+	AssertZero(t, b.Len())
+}
+
+func TestBuffer_PublicAppend(t *testing.T) {
+	b := Buffer{}
+	b.AppendInt(-12)
+	_ = b.WriteByte(' ')
+	b.AppendTime(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.DateOnly)
+	_ = b.WriteByte(' ')
+	b.AppendDuration(1500 * time.Millisecond)
+	AssertEqual(t, "-12 2024-03-01 1.5s", b.String())
+}
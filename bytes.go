@@ -0,0 +1,66 @@
+package console
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+)
+
+// ByteSize is a size in bytes. It is rendered with binary unit prefixes
+// and a precision depending on its magnitude, like "512B", "1.50KiB",
+// "12.5MiB" or "300GiB".
+type ByteSize int64
+
+func (b ByteSize) String() string {
+	return string(appendByteSize(nil, b))
+}
+
+// Bytes returns an attribute holding the size n in bytes as a ByteSize:
+//
+//	logger.Info("downloaded", console.Bytes("size", resp.ContentLength))
+func Bytes(key string, n int64) slog.Attr {
+	return slog.Any(key, ByteSize(n))
+}
+
+// appendByteSize appends b with a binary unit prefix, keeping 3
+// significant digits.
+func appendByteSize(dst []byte, b ByteSize) []byte {
+	const unit = 1024
+	if b > -unit && b < unit {
+		dst = strconv.AppendInt(dst, int64(b), 10)
+		return append(dst, 'B')
+	}
+	v := float64(b)
+	i := -1
+	for (v <= -unit || v >= unit) && i < len("KMGTPE")-1 {
+		v /= unit
+		i++
+	}
+	prec := sizePrecision(v)
+	// Rounding may reach the next unit or decade, like 1048575B which
+	// would be rendered as "1024KiB" instead of "1.00MiB"
+	pow := math.Pow10(prec)
+	r := math.Abs(math.Round(v*pow) / pow)
+	if r >= unit && i < len("KMGTPE")-1 {
+		v /= unit
+		i++
+		prec = sizePrecision(v)
+	} else {
+		prec = sizePrecision(r)
+	}
+	dst = strconv.AppendFloat(dst, v, 'f', prec, 64)
+	dst = append(dst, "KMGTPE"[i])
+	return append(dst, "iB"...)
+}
+
+// sizePrecision returns the number of decimals to render v with,
+// keeping 3 significant digits.
+func sizePrecision(v float64) int {
+	switch v = math.Abs(v); {
+	case v < 10:
+		return 2
+	case v < 100:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package console
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestByteSize(t *testing.T) {
+	for _, tc := range []struct {
+		b        ByteSize
+		expected string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{-512, "-512B"},
+		{1024, "1.00KiB"},
+		{1536, "1.50KiB"},
+		{-1536, "-1.50KiB"},
+		{12_800 << 10, "12.5MiB"},
+		{300 << 30, "300GiB"},
+		{3 << 40, "3.00TiB"},
+		{1 << 62, "4.00EiB"},
+		{1048575, "1.00MiB"},
+		{-1048575, "-1.00MiB"},
+		{10235, "10.0KiB"},
+		{102350, "100KiB"},
+	} {
+		AssertEqual(t, tc.expected, tc.b.String())
+	}
+}
+
+func TestBytes(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.AddAttrs(Bytes("size", 5<<20))
+	AssertEqual(t, "INF msg size=5.00MiB\n", string(Render(rec, &HandlerOptions{NoColor: true})))
+}
@@ -0,0 +1,79 @@
+package console
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// maxKeySets bounds the number of key sets remembered by a changeTracker.
+// When it's reached, the tracker starts over.
+const maxKeySets = 1024
+
+// changeTracker remembers the attribute values of the last record
+// logged for each set of attribute keys.
+type changeTracker struct {
+	mu   sync.Mutex
+	last map[string][]string // Values, by key set
+}
+
+func newChangeTracker() *changeTracker {
+	return &changeTracker{last: make(map[string][]string)}
+}
+
+// observe records values as the last ones for keys, and reports which of
+// them changed since the previous record with the same keys. It returns
+// nil if there is no such record.
+func (t *changeTracker) observe(keys string, values []string) []bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, ok := t.last[keys]
+	if !ok && len(t.last) >= maxKeySets {
+		clear(t.last)
+	}
+	t.last[keys] = values
+	if !ok {
+		return nil
+	}
+	changed := make([]bool, len(values))
+	for i, v := range values {
+		changed[i] = v != prev[i]
+	}
+	return changed
+}
+
+// writeChangedAttrs writes the attributes of rec within the groups g, with
+// their values styled after whether they changed since the previous record
+// with the same keys.
+func (e *encoder) writeChangedAttrs(buf *buffer, rec slog.Record, g *groups) {
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	values := make([]string, 0, rec.NumAttrs())
+	var keys strings.Builder
+	keys.Write(g.prefix)
+	rec.Attrs(func(a slog.Attr) bool {
+		a.Value = a.Value.Resolve()
+		attrs = append(attrs, a)
+		values = append(values, a.Value.String())
+		keys.WriteString(a.Key)
+		keys.WriteByte(0)
+		return true
+	})
+	changed := e.changes.observe(keys.String(), values)
+	for i, a := range attrs {
+		if changed == nil {
+			e.writeGroupedAttr(buf, a, g)
+			continue
+		}
+		ee := *e
+		ee.opts.Theme = valueTheme{wrappedTheme{e.opts.Theme}, changed[i]}
+		ee.writeGroupedAttr(buf, a, g)
+	}
+}
+
+// valueTheme is a theme whose values are styled after whether they changed.
+type valueTheme struct {
+	wrappedTheme
+	changed bool
+}
+
+func (t valueTheme) AttrValue() ANSIMod { return changedStyle(t.Theme, t.changed) }
@@ -0,0 +1,46 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestChangeTracker(t *testing.T) {
+	c := newChangeTracker()
+	AssertEqual(t, 0, len(c.observe("a\x00b\x00", []string{"1", "2"})))
+	AssertEqual(t, 0, len(c.observe("a\x00", []string{"1"})))
+	changed := c.observe("a\x00b\x00", []string{"1", "3"})
+	AssertEqual(t, 2, len(changed))
+	AssertEqual(t, false, changed[0])
+	AssertEqual(t, true, changed[1])
+}
+
+func TestHandler_HighlightChanges(t *testing.T) {
+	theme := NewDefaultTheme()
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{Theme: theme, HighlightChanges: true, HideTime: true, HideLevel: true})
+	key := func(k string) string {
+		return string(theme.AttrKey()) + k + "=" + string(ResetMod)
+	}
+	style := func(v string, m ANSIMod) string { return string(m) + v + string(ResetMod) }
+	msg := style("status", theme.Message())
+	for _, n := range []int{1, 1, 2} {
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "status", 0)
+		rec.Add("n", n, "state", "up")
+		AssertNoError(t, h.Handle(context.Background(), rec))
+	}
+	AssertEqual(t, msg+" "+key("n")+"1 "+key("state")+"up\n"+
+		msg+" "+key("n")+style("1", changedStyle(theme, false))+" "+key("state")+style("up", changedStyle(theme, false))+"\n"+
+		msg+" "+key("n")+style("2", changedStyle(theme, true))+" "+key("state")+style("up", changedStyle(theme, false))+"\n",
+		buf.String())
+
+	buf.Reset()
+	h2 := h.WithOptions(func(o *HandlerOptions) { o.NoColor = true })
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "status", 0)
+	rec.Add("n", 3, "state", "up")
+	AssertNoError(t, h2.Handle(context.Background(), rec))
+	AssertEqual(t, "status n=3 state=up\n", buf.String())
+}
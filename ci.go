@@ -0,0 +1,97 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// CIMarkers selects the markers written around spans so that the output
+// of CI runs renders as collapsible sections in the web UI of the CI
+// system.
+type CIMarkers int
+
+const (
+	// CIMarkersNone writes no marker.
+	CIMarkersNone CIMarkers = iota
+	// CIMarkersGitHub writes GitHub Actions "::group::" and "::endgroup::"
+	// workflow commands. As GitHub Actions doesn't support nested groups,
+	// only the outermost spans are grouped.
+	CIMarkersGitHub
+	// CIMarkersGitLab writes GitLab CI "section_start" and "section_end"
+	// markers.
+	CIMarkersGitLab
+)
+
+// DetectCIMarkers returns the markers for the CI system the program runs
+// in, as reported by the environment, or CIMarkersNone:
+//
+//	opts.CIMarkers = console.DetectCIMarkers()
+func DetectCIMarkers() CIMarkers {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return CIMarkersGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return CIMarkersGitLab
+	default:
+		return CIMarkersNone
+	}
+}
+
+// ciSectionID numbers the GitLab sections, whose names must be unique.
+var ciSectionID atomic.Uint64
+
+// openSection writes the marker opening a collapsible section titled
+// title, and returns the function writing the marker closing it, or nil
+// if no section was opened.
+func (h *Handler) openSection(title string) func() {
+	if h.disabled() {
+		return nil
+	}
+	var open []byte
+	var end func() string
+	switch h.opts.CIMarkers {
+	case CIMarkersGitHub:
+		if h.opts.Indent > 0 {
+			return nil
+		}
+		open = append(append([]byte("::group::"), githubEscaper.Replace(title)...), '\n')
+		end = func() string { return "::endgroup::\n" }
+	case CIMarkersGitLab:
+		name := "section_" + strconv.FormatUint(ciSectionID.Add(1), 10)
+		open = append([]byte("\x1b[0Ksection_start:"+unixNow()+":"+name+"[collapsed=true]\r\x1b[0K"), escapeNewlines(title)...)
+		open = append(open, '\n')
+		end = func() string { return "\x1b[0Ksection_end:" + unixNow() + ":" + name + "\r\x1b[0K\n" }
+	default:
+		return nil
+	}
+	if err := h.writeMarker(open); err != nil {
+		return nil
+	}
+	return func() { _ = h.writeMarker([]byte(end())) }
+}
+
+// githubEscaper escapes the data of GitHub Actions workflow commands,
+// which would otherwise be cut at line breaks.
+var githubEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// writeMarker writes the section marker b like a record at info level,
+// to the output of h and to its mirrors, if they accept such records.
+func (h *Handler) writeMarker(b []byte) error {
+	var err error
+	if h.levelEnabled(context.Background(), slog.LevelInfo) && !h.out.discard.Load() {
+		_, err = h.out.Write(b)
+	}
+	if e := h.writeMirrors(b, slog.LevelInfo); err == nil {
+		err = e
+	}
+	return err
+}
+
+func unixNow() string {
+	return strconv.FormatInt(time.Now().Unix(), 10)
+}
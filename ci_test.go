@@ -0,0 +1,66 @@
+package console
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestSpan_CIMarkers(t *testing.T) {
+	run := func(markers CIMarkers) string {
+		buf := bytes.Buffer{}
+		logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, CIMarkers: markers}))
+		span := StartSpan(logger, "build")
+		sub := StartSpan(span.Logger(), "compile")
+		sub.End()
+		span.End()
+		return regexp.MustCompile(`elapsed=\S+`).ReplaceAllString(buf.String(), "elapsed=X")
+	}
+
+	AssertEqual(t, "::group::build\n"+
+		"INF build\n"+
+		"INF   compile\n"+
+		"INF   compile done elapsed=X\n"+
+		"INF build done elapsed=X\n"+
+		"::endgroup::\n", run(CIMarkersGitHub))
+
+	AssertEqual(t, true, regexp.MustCompile(`^\x1b\[0Ksection_start:\d+:section_(\d+)\[collapsed=true\]\r\x1b\[0Kbuild\n`+
+		`INF build\n`+
+		`\x1b\[0Ksection_start:\d+:section_\d+\[collapsed=true\]\r\x1b\[0Kcompile\n`+
+		`INF   compile\n`+
+		`INF   compile done elapsed=X\n`+
+		`\x1b\[0Ksection_end:\d+:section_\d+\r\x1b\[0K\n`+
+		`INF build done elapsed=X\n`+
+		`\x1b\[0Ksection_end:\d+:section_\d+\r\x1b\[0K\n$`).MatchString(run(CIMarkersGitLab)))
+
+	AssertEqual(t, "INF build\nINF   compile\nINF   compile done elapsed=X\nINF build done elapsed=X\n", run(CIMarkersNone))
+}
+
+func TestDetectCIMarkers(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+	AssertEqual(t, CIMarkersNone, DetectCIMarkers())
+	t.Setenv("GITLAB_CI", "true")
+	AssertEqual(t, CIMarkersGitLab, DetectCIMarkers())
+	t.Setenv("GITHUB_ACTIONS", "true")
+	AssertEqual(t, CIMarkersGitHub, DetectCIMarkers())
+}
+
+func TestSpan_CIMarkersEscaping(t *testing.T) {
+	buf := bytes.Buffer{}
+	mirror := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{
+		NoColor:   true,
+		HideTime:  true,
+		Level:     slog.LevelWarn,
+		CIMarkers: CIMarkersGitHub,
+		Mirrors:   []Mirror{{W: &mirror, Level: slog.LevelInfo}},
+	}))
+	StartSpan(logger, "100%\r\ndone").End()
+	AssertEqual(t, "", buf.String())
+	AssertEqual(t, "::group::100%25%0D%0Adone\n"+
+		"INF 100%\r\ndone\n"+
+		"INF 100%\r\ndone done elapsed=X\n"+
+		"::endgroup::\n", regexp.MustCompile(`elapsed=\S+`).ReplaceAllString(mirror.String(), "elapsed=X"))
+}
@@ -0,0 +1,167 @@
+package console
+
+import (
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Header columns whose width is tracked by headerColumns.
+const (
+	colTimestamp = iota
+	colLevel
+	colSource
+	numCols
+)
+
+// columnWindow is the number of records after which the width of a column
+// shrinks back if no record reached it.
+const columnWindow = 64
+
+// headerColumns tracks the rolling maximum width of the header columns.
+// Updates are not atomic as a whole, which may at worst cause some
+// misalignment under heavy concurrency.
+type headerColumns [numCols]columnWidth
+
+type columnWidth struct {
+	max atomic.Int32
+	age atomic.Int32 // Number of records since max was last reached
+}
+
+// observe records a value of width w, and returns the width
+// the column must be padded to.
+func (c *columnWidth) observe(w int) int {
+	m := int(c.max.Load())
+	if w >= m {
+		c.max.Store(int32(w))
+		c.age.Store(0)
+		return w
+	}
+	if c.age.Add(1) > columnWindow {
+		c.max.Store(int32(w))
+		c.age.Store(0)
+	}
+	return m
+}
+
+// padColumn pads the header column col, written in buf from start,
+// to its current width. It returns the end of the column.
+func (e *encoder) padColumn(buf *buffer, col int, start int) int {
+	if !e.opts.AdaptiveHeaders {
+		return buf.Len()
+	}
+	w := visibleLen((*buf)[start:])
+	for pad := e.cols[col].observe(w) - w; pad > 0; pad-- {
+		buf.AppendByte(' ')
+	}
+	return buf.Len()
+}
+
+// visibleLen returns the number of runes in b, ignoring ANSI escape sequences.
+func visibleLen(b []byte) int {
+	n := 0
+	for i := 0; i < len(b); {
+		if l := csiLen(b[i:]); l > 0 {
+			i += l
+			continue
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		i += size
+		n++
+	}
+	return n
+}
+
+// csiLen returns the length of the CSI escape sequence at the start of b,
+// like "\x1b[31m", or 0 if b doesn't start with one. A sequence missing
+// its final byte spans until the end of b.
+func csiLen(b []byte) int {
+	if len(b) < 2 || b[0] != '\x1b' || b[1] != '[' {
+		return 0
+	}
+	i := 2
+	for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+		i++
+	}
+	return min(i+1, len(b))
+}
+
+// valueColumns tracks the rolling maximum width of the values of each
+// attribute key.
+type valueColumns struct {
+	mu     sync.Mutex
+	widths map[string]*columnWidth // By key, including the group prefix
+}
+
+func newValueColumns() *valueColumns {
+	return &valueColumns{widths: make(map[string]*columnWidth)}
+}
+
+// column returns the width of the values of the attribute key
+// in the group prefix.
+func (c *valueColumns) column(prefix []byte, key string) *columnWidth {
+	// Use a stack allocated array to build the full key in the common case
+	var scratch [64]byte
+	k := append(append(scratch[:0], prefix...), key...)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.widths[string(k)]
+	if !ok {
+		if len(c.widths) >= maxKeySets {
+			clear(c.widths)
+		}
+		w = new(columnWidth)
+		c.widths[string(k)] = w
+	}
+	return w
+}
+
+// padValue pads the value of the attribute key in the group prefix,
+// written in buf from start, to the current width of the key's values.
+// The padding is left pending if e has a pad, so that the last value of
+// a line isn't followed by whitespace.
+func (e *encoder) padValue(buf *buffer, prefix []byte, key string, start int) {
+	if !e.opts.AlignValues || e.values == nil {
+		return
+	}
+	w := visibleLen((*buf)[start:])
+	pad := e.values.column(prefix, key).observe(w) - w
+	if e.pad != nil {
+		*e.pad = pad
+		return
+	}
+	for ; pad > 0; pad-- {
+		buf.AppendByte(' ')
+	}
+}
+
+// withPad returns the encoder to use to keep the padding of the values
+// pending in pad. With AlignValues, that encoder is a copy of e made in
+// scratch.
+func (e *encoder) withPad(pad *int, scratch *encoder) *encoder {
+	if !e.opts.AlignValues {
+		return e
+	}
+	*scratch = *e
+	scratch.pad = pad
+	return scratch
+}
+
+// writePad writes the pending padding of the previous value,
+// before the separator of the next attribute.
+func (e *encoder) writePad(buf *buffer) {
+	if e.pad == nil {
+		return
+	}
+	for ; *e.pad > 0; *e.pad-- {
+		buf.AppendByte(' ')
+	}
+}
+
+// dropPad discards the pending padding of the previous value, which
+// is not followed by another attribute on the same line.
+func (e *encoder) dropPad() {
+	if e.pad != nil {
+		*e.pad = 0
+	}
+}
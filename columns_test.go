@@ -0,0 +1,68 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandler_AdaptiveHeaders(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, AdaptiveHeaders: true, Level: slog.LevelDebug})
+	for _, l := range []slog.Level{slog.LevelInfo, slog.LevelWarn + 2, slog.LevelInfo} {
+		AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, l, "msg", 0)))
+	}
+	AssertEqual(t, "INF msg\nWRN+2 msg\nINF   msg\n", buf.String())
+
+	buf.Reset()
+	h2 := h.WithOptions(func(o *HandlerOptions) { o.AdaptiveHeaders = false })
+	AssertNoError(t, h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)))
+	AssertEqual(t, "INF msg\n", buf.String())
+}
+
+func TestHandler_AlignValues(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, AlignValues: true})
+	for _, path := range []string{"/", "/users/42", "/health"} {
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "req", 0)
+		rec.Add("path", path, "status", 200)
+		AssertNoError(t, h.Handle(context.Background(), rec))
+	}
+	AssertEqual(t, "INF req path=/ status=200\n"+
+		"INF req path=/users/42 status=200\n"+
+		"INF req path=/health   status=200\n", buf.String())
+
+	buf.Reset()
+	slog.New(h.WithGroup("g")).Info("req", "path", "/")
+	AssertEqual(t, "INF req g.path=/\n", buf.String())
+	// The last value isn't padded
+	buf.Reset()
+	logger := slog.New(h)
+	logger.Info("req", "path", "/users/42")
+	logger.Info("req", "path", "/")
+	logger.With("path", "/").Info("req")
+	logger.Info("req", slog.Group("g", "path", "/"), "status", 200)
+	AssertEqual(t, "INF req path=/users/42\n"+
+		"INF req path=/\n"+
+		"INF req path=/\n"+
+		"INF req g.path=/ status=200\n", buf.String())
+}
+
+func TestColumnWidth(t *testing.T) {
+	var c columnWidth
+	AssertEqual(t, 3, c.observe(3))
+	AssertEqual(t, 5, c.observe(5))
+	for i := 0; i < columnWindow; i++ {
+		AssertEqual(t, 5, c.observe(3))
+	}
+	AssertEqual(t, 5, c.observe(3))
+	AssertEqual(t, 3, c.observe(3))
+}
+
+func TestVisibleLen(t *testing.T) {
+	AssertEqual(t, 0, visibleLen(nil))
+	AssertEqual(t, 4, visibleLen([]byte("\x1b[1;31mERR\x1b[0m ")))
+	AssertEqual(t, 3, visibleLen([]byte("h─y")))
+}
@@ -0,0 +1,125 @@
+// Package consolehttp provides a net/http middleware logging requests
+// through a console handler, for readable access logs during local
+// development:
+//
+//	INF GET /users/42 status=200 size=1.21KiB latency=3.2ms
+//	WRN GET /favicon.ico status=404 size=19B latency=41µs
+package consolehttp
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/phsym/console-slog"
+)
+
+// Keys of the attributes logged for each request.
+const (
+	StatusKey  = "status"
+	SizeKey    = "size"
+	LatencyKey = "latency"
+	PanicKey   = "panic"
+)
+
+// Middleware returns a middleware logging each request served by the
+// handler it wraps with logger, once it's done. The message is the
+// request method and path, and the attributes are the response status,
+// its size as a console.ByteSize, and the latency as a console.Elapsed.
+// Requests are logged at info level, at warn level for 4xx statuses and
+// at error level for 5xx statuses.
+//
+// If the wrapped handler panics, the request is logged at error level with
+// a 500 status and the panic value, then the panic goes on to net/http.
+// Panics with http.ErrAbortHandler, which abort a response on purpose,
+// are not logged.
+func Middleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if p := recover(); p != nil {
+					if p != http.ErrAbortHandler {
+						logger.LogAttrs(r.Context(), slog.LevelError, r.Method+" "+r.URL.Path,
+							slog.Int(StatusKey, http.StatusInternalServerError),
+							slog.String(PanicKey, fmt.Sprint(p)),
+							slog.Any(LatencyKey, console.Elapsed(time.Since(start))),
+						)
+					}
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+			logger.LogAttrs(r.Context(), level(rw.status), r.Method+" "+r.URL.Path,
+				slog.Int(StatusKey, rw.status),
+				console.Bytes(SizeKey, rw.size),
+				slog.Any(LatencyKey, console.Elapsed(time.Since(start))),
+			)
+		})
+	}
+}
+
+// level returns the level requests answered with status are logged at.
+func level(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, for streamed responses.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, for protocols taking over the
+// connection, like WebSocket.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,65 @@
+package consolehttp
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/phsym/console-slog"
+	"github.com/phsym/console-slog/consoletest"
+)
+
+func TestMiddleware(t *testing.T) {
+	r, h := consoletest.NewRecorder(&console.HandlerOptions{NoColor: true})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	srv := Middleware(slog.New(h))(mux)
+
+	for _, path := range []string{"/hello", "/missing", "/fail"} {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	consoletest.AssertLogged(t, r, slog.LevelInfo, "GET /hello", slog.Int(StatusKey, 200), console.Bytes(SizeKey, 11))
+	consoletest.AssertLogged(t, r, slog.LevelWarn, "GET /missing", slog.Int(StatusKey, 404))
+	consoletest.AssertLogged(t, r, slog.LevelError, "GET /fail", slog.Int(StatusKey, 503), console.Bytes(SizeKey, 0))
+}
+
+func TestMiddleware_Flusher(t *testing.T) {
+	_, h := consoletest.NewRecorder(nil)
+	var flusher, hijacker bool
+	srv := Middleware(slog.New(h))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flusher = w.(http.Flusher)
+		_, hijacker = w.(http.Hijacker)
+		w.(http.Flusher).Flush()
+		_, _, err := w.(http.Hijacker).Hijack()
+		if err != http.ErrNotSupported {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+	}))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !flusher || !hijacker || !rec.Flushed {
+		t.Errorf("expected a flushed writer, got flusher=%v hijacker=%v flushed=%v", flusher, hijacker, rec.Flushed)
+	}
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	r, h := consoletest.NewRecorder(nil)
+	srv := Middleware(slog.New(h))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("expected the panic to go on, got %v", p)
+			}
+		}()
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+	}()
+	consoletest.AssertLogged(t, r, slog.LevelError, "GET /boom", slog.Int(StatusKey, 500), slog.String(PanicKey, "boom"))
+}
@@ -0,0 +1,85 @@
+package consoletest
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/phsym/console-slog"
+)
+
+// AssertLogged reports an error if output holds no line logged at level,
+// whose message contains msgSubstring, and having all the given attributes.
+// Attributes within groups can be given either as slog.Group or with dotted
+// keys. Expected values are rendered the same way the handler renders them,
+// so colors and formatting never get in the way.
+//
+// output is typically a *bytes.Buffer the handler writes to, or a Recorder.
+// It is parsed with the default options.
+func AssertLogged(t testing.TB, output fmt.Stringer, level slog.Level, msgSubstring string, attrs ...slog.Attr) {
+	t.Helper()
+	if _, ok := findLogged(output, level, msgSubstring, attrs); !ok {
+		t.Errorf("no %s record with message containing %q and attributes %v found in output:\n%s", level, msgSubstring, attrs, output)
+	}
+}
+
+// AssertNotLogged reports an error if output holds a line logged at level,
+// whose message contains msgSubstring, and having all the given attributes.
+// See AssertLogged for details.
+func AssertNotLogged(t testing.TB, output fmt.Stringer, level slog.Level, msgSubstring string, attrs ...slog.Attr) {
+	t.Helper()
+	if e, ok := findLogged(output, level, msgSubstring, attrs); ok {
+		t.Errorf("unexpected %s record found: %s", level, e.Line)
+	}
+}
+
+var valueEncoder = console.NewEncoder(&console.HandlerOptions{NoColor: true})
+
+func findLogged(output fmt.Stringer, level slog.Level, msgSubstring string, attrs []slog.Attr) (Entry, bool) {
+	want := map[string]string{}
+	flattenAttrs(want, "", attrs)
+	for _, line := range strings.Split(output.String(), "\n") {
+		fields, err := console.ParseLine(line, nil)
+		if err != nil {
+			continue
+		}
+		e := Entry{Line: line, Fields: fields}
+		if e.Level() != level || !strings.Contains(e.Message(), msgSubstring) {
+			continue
+		}
+		if hasAttrs(e, want) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+func hasAttrs(e Entry, want map[string]string) bool {
+	for k, v := range want {
+		if got, ok := e.Attr(k); !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+func flattenAttrs(dst map[string]string, prefix string, attrs []slog.Attr) {
+	for _, a := range attrs {
+		key := a.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		v := a.Value.Resolve()
+		if v.Kind() == slog.KindGroup {
+			if a.Key == "" {
+				key = prefix
+			}
+			flattenAttrs(dst, key, v.Group())
+			continue
+		}
+		var buf console.Buffer
+		valueEncoder.WriteValue(&buf, v)
+		dst[key] = buf.String()
+	}
+}
@@ -0,0 +1,30 @@
+package consoletest
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/phsym/console-slog"
+)
+
+func TestAssertLogged(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(console.NewHandler(&buf, nil))
+	logger.Warn("request failed", "status", 503, "err", errors.New("unavailable"), slog.Group("req", "took", 2*time.Second))
+	logger.Info("done")
+
+	AssertLogged(t, &buf, slog.LevelWarn, "failed")
+	AssertLogged(t, &buf, slog.LevelWarn, "request", slog.Int("status", 503), slog.Any("err", errors.New("unavailable")))
+	AssertLogged(t, &buf, slog.LevelWarn, "", slog.Group("req", "took", 2*time.Second))
+	AssertLogged(t, &buf, slog.LevelWarn, "", slog.Duration("req.took", 2*time.Second))
+	AssertLogged(t, &buf, slog.LevelInfo, "done")
+	AssertNotLogged(t, &buf, slog.LevelError, "")
+	AssertNotLogged(t, &buf, slog.LevelWarn, "request", slog.Int("status", 500))
+
+	r, h := NewRecorder(nil)
+	slog.New(h).Error("boom", "code", 12)
+	AssertLogged(t, r, slog.LevelError, "boom", slog.Int("code", 12))
+}
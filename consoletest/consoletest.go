@@ -0,0 +1,145 @@
+// Package consoletest provides utilities to capture and inspect the output
+// of a console handler in unit tests.
+package consoletest
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/phsym/console-slog"
+)
+
+// Entry is a rendered log line, along with its parsed fields.
+type Entry struct {
+	// Line is the rendered line, without the trailing newline.
+	Line string
+	// Fields holds the parsed fields, as returned by console.ParseLine.
+	Fields map[string]any
+}
+
+// Level returns the level of the entry.
+func (e Entry) Level() slog.Level {
+	l, _ := e.Fields[slog.LevelKey].(slog.Level)
+	return l
+}
+
+// Message returns the message of the entry.
+func (e Entry) Message() string {
+	m, _ := e.Fields[slog.MessageKey].(string)
+	return m
+}
+
+// Attr returns the value of the attribute with the given key. Keys of
+// attributes within groups are dot separated, like "group.key".
+func (e Entry) Attr(key string) (any, bool) {
+	var v any = e.Fields
+	for _, k := range strings.Split(key, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Recorder is an io.Writer capturing the lines written by a console
+// handler. It is safe for concurrent use.
+type Recorder struct {
+	opts    console.HandlerOptions
+	mu      sync.Mutex
+	entries []Entry
+	partial []byte
+}
+
+// NewRecorder creates a Recorder along with a handler writing to it,
+// configured with opts. If opts is nil, the default options are used.
+func NewRecorder(opts *console.HandlerOptions) (*Recorder, *console.Handler) {
+	r := new(Recorder)
+	h := console.NewHandler(r, opts)
+	r.opts = h.Options()
+	return r, h
+}
+
+// Write implements io.Writer. Each complete line is parsed and recorded.
+// Lines which cannot be parsed are recorded with nil Fields.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.partial = append(r.partial, p...)
+	for {
+		i := bytes.IndexByte(r.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := string(r.partial[:i])
+		r.partial = r.partial[i+1:]
+		fields, _ := console.ParseLine(line, &r.opts)
+		r.entries = append(r.entries, Entry{Line: line, Fields: fields})
+	}
+	return len(p), nil
+}
+
+// Entries returns all the recorded entries.
+func (r *Recorder) Entries() []Entry {
+	return r.filter(func(Entry) bool { return true })
+}
+
+// Lines returns all the recorded lines.
+func (r *Recorder) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]string, len(r.entries))
+	for i, e := range r.entries {
+		lines[i] = e.Line
+	}
+	return lines
+}
+
+// String returns all the recorded lines, each one terminated by a newline.
+func (r *Recorder) String() string {
+	var sb strings.Builder
+	for _, l := range r.Lines() {
+		sb.WriteString(l)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ByLevel returns the entries logged at exactly the given level.
+func (r *Recorder) ByLevel(level slog.Level) []Entry {
+	return r.filter(func(e Entry) bool { return e.Fields != nil && e.Level() == level })
+}
+
+// ByKey returns the entries having an attribute with the given key.
+// See Entry.Attr for the key syntax.
+func (r *Recorder) ByKey(key string) []Entry {
+	return r.filter(func(e Entry) bool {
+		_, ok := e.Attr(key)
+		return ok
+	})
+}
+
+// Reset drops all the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+	r.partial = nil
+}
+
+func (r *Recorder) filter(f func(Entry) bool) []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []Entry
+	for _, e := range r.entries {
+		if f(e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
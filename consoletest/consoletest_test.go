@@ -0,0 +1,43 @@
+package consoletest
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/phsym/console-slog"
+)
+
+func TestRecorder(t *testing.T) {
+	r, h := NewRecorder(&console.HandlerOptions{NoColor: true})
+	logger := slog.New(h).With("app", "test")
+	logger.Info("started", "port", 8080)
+	logger.WithGroup("db").Warn("slow query", "took", "3s")
+	logger.Error("failed")
+	logger.Debug("hidden")
+
+	if n := len(r.Entries()); n != 3 {
+		t.Fatalf("expected 3 entries, got %d", n)
+	}
+	warns := r.ByLevel(slog.LevelWarn)
+	if len(warns) != 1 || warns[0].Message() != "slow query" {
+		t.Errorf("unexpected warnings: %v", warns)
+	}
+	if v, ok := warns[0].Attr("db.took"); !ok || v != "3s" {
+		t.Errorf("expected db.took=3s, got %v", v)
+	}
+	if n := len(r.ByKey("app")); n != 3 {
+		t.Errorf("expected 3 entries with key app, got %d", n)
+	}
+	if n := len(r.ByKey("port")); n != 1 {
+		t.Errorf("expected 1 entry with key port, got %d", n)
+	}
+	if l := r.Lines()[2]; !strings.HasSuffix(l, "ERR failed app=test") {
+		t.Errorf("unexpected line %q", l)
+	}
+
+	r.Reset()
+	if n := len(r.Entries()); n != 0 {
+		t.Errorf("expected no entries after reset, got %d", n)
+	}
+}
@@ -0,0 +1,168 @@
+package consoletest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/phsym/console-slog"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to a non-empty
+// value, makes Golden.Assert rewrite the golden files instead of comparing them.
+const UpdateGoldenEnv = "CONSOLETEST_UPDATE_GOLDEN"
+
+// GoldenTime is the timestamp given to every non-zero record rendered by a Golden.
+var GoldenTime = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Golden renders records deterministically so that they can be compared
+// against golden files: colors are disabled, the timestamp of every record
+// is replaced by GoldenTime, and attributes are sorted by key.
+type Golden struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	h   slog.Handler
+}
+
+// NewGolden creates a Golden. opts may be used to customize the output, but
+// NoColor is always forced. If opts is nil, the default options are used.
+func NewGolden(opts *console.HandlerOptions) *Golden {
+	var o console.HandlerOptions
+	if opts != nil {
+		o = *opts
+	}
+	o.NoColor = true
+	g := new(Golden)
+	g.h = &goldenHandler{console.NewHandler(lockedWriter{&g.mu, &g.buf}, &o)}
+	return g
+}
+
+// Handler returns the handler rendering into g.
+func (g *Golden) Handler() slog.Handler {
+	return g.h
+}
+
+// String returns the output rendered so far.
+func (g *Golden) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+// Assert compares the output rendered so far with the content of the golden
+// file at path, and reports the differing lines. If the UpdateGoldenEnv
+// environment variable is set, the golden file is written instead.
+func (g *Golden) Assert(t testing.TB, path string) {
+	t.Helper()
+	got := g.String()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %s (set %s=1 to create it)", err, UpdateGoldenEnv)
+	}
+	if d := diffLines(string(want), got); d != "" {
+		t.Errorf("output does not match golden file %s (-want +got):\n%s", path, d)
+	}
+}
+
+// diffLines returns a description of the lines which differ
+// between want and got, or an empty string if they are equal.
+func diffLines(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	var sb strings.Builder
+	for i := 0; i < max(len(wl), len(gl)); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			continue
+		}
+		fmt.Fprintf(&sb, "line %d:\n", i+1)
+		if i < len(wl) {
+			fmt.Fprintf(&sb, "- %s\n", w)
+		}
+		if i < len(gl) {
+			fmt.Fprintf(&sb, "+ %s\n", g)
+		}
+	}
+	return sb.String()
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// goldenHandler normalizes the records before handing them to the console handler.
+type goldenHandler struct {
+	h slog.Handler
+}
+
+func (g *goldenHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return g.h.Enabled(ctx, l)
+}
+
+func (g *goldenHandler) Handle(ctx context.Context, rec slog.Record) error {
+	ts := rec.Time
+	if !ts.IsZero() {
+		ts = GoldenTime
+	}
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	r := slog.NewRecord(ts, rec.Level, rec.Message, rec.PC)
+	r.AddAttrs(sortAttrs(attrs)...)
+	return g.h.Handle(ctx, r)
+}
+
+func (g *goldenHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &goldenHandler{g.h.WithAttrs(sortAttrs(slices.Clone(attrs)))}
+}
+
+func (g *goldenHandler) WithGroup(name string) slog.Handler {
+	return &goldenHandler{g.h.WithGroup(name)}
+}
+
+func sortAttrs(attrs []slog.Attr) []slog.Attr {
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	for i, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			attrs[i].Value = slog.GroupValue(sortAttrs(slices.Clone(a.Value.Group()))...)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,25 @@
+package consoletest
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestGolden(t *testing.T) {
+	g := NewGolden(nil)
+	logger := slog.New(g.Handler()).With("z", 1, "a", 2)
+	logger.Info("hello", "b", "x", "a", time.Second, slog.Group("g", "y", 1, "x", 2))
+	logger.WithGroup("sub").Warn("world", "k", "v")
+	g.Assert(t, "testdata/golden.txt")
+}
+
+func TestDiffLines(t *testing.T) {
+	if d := diffLines("a\nb\n", "a\nb\n"); d != "" {
+		t.Errorf("expected no diff, got %q", d)
+	}
+	want := "line 2:\n- b\n+ c\nline 3:\n+ d\n"
+	if d := diffLines("a\nb", "a\nc\nd"); d != want {
+		t.Errorf("expected %q, got %q", want, d)
+	}
+}
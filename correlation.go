@@ -0,0 +1,105 @@
+package console
+
+import (
+	"log/slog"
+)
+
+// Gutters drawn before records when HandlerOptions.CorrelationKey is set.
+const (
+	gutterStart = "┌ " // First record of a run sharing the same correlation value
+	gutterCont  = "│ " // Following records of the run
+	gutterNone  = "  " // Records without correlation value
+)
+
+// correlationColors are the styles the gutter is drawn with, picked
+// from the correlation value.
+var correlationColors = []ANSIMod{
+	ToANSICode(Cyan),
+	ToANSICode(Magenta),
+	ToANSICode(Yellow),
+	ToANSICode(Blue),
+	ToANSICode(Green),
+	ToANSICode(BrightCyan),
+	ToANSICode(BrightMagenta),
+	ToANSICode(BrightBlue),
+}
+
+// gutters holds the pre-rendered gutters, so that writing them
+// does not allocate. Indexed by color, then start or continuation.
+var gutters = func() (g [][2][]byte) {
+	for _, c := range correlationColors {
+		g = append(g, [2][]byte{
+			[]byte(string(c) + gutterStart + string(ResetMod)),
+			[]byte(string(c) + gutterCont + string(ResetMod)),
+		})
+	}
+	return g
+}()
+
+var plainGutters = [2][]byte{[]byte(gutterStart), []byte(gutterCont)}
+
+// correlationValue returns the value of the correlation key in rec, or in
+// the attributes added with WithAttrs. Only top level attributes are considered.
+func (h *Handler) correlationValue(rec slog.Record) (val string, found bool) {
+	key := h.opts.CorrelationKey
+	if len(h.groups.names) == 0 {
+		rec.Attrs(func(a slog.Attr) bool {
+			if a.Key == key {
+				val, found = a.Value.Resolve().String(), true
+				return false
+			}
+			return true
+		})
+	}
+	if found {
+		return val, true
+	}
+	for _, a := range h.ctxAttrs {
+		if len(a.groups.names) == 0 && a.attr.Key == key {
+			return a.attr.Value.Resolve().String(), true
+		}
+	}
+	return "", false
+}
+
+// writeCorrelated writes the record in b, preceded by a gutter showing
+// whether it belongs to the same run of correlated records as the previous one.
+func (o *output) writeCorrelated(b *buffer, val string, found, color bool) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var gutter []byte
+	if !found {
+		gutter = []byte(gutterNone)
+	} else {
+		kind := 0
+		if o.hasCorrelation && o.lastCorrelation == val {
+			kind = 1
+		}
+		if color {
+			gutter = gutters[hashString(val)%uint32(len(gutters))][kind]
+		} else {
+			gutter = plainGutters[kind]
+		}
+	}
+	o.lastCorrelation, o.hasCorrelation = val, found
+	if o.overlay != nil {
+		o.overlay.Clear(o.w)
+		defer o.overlay.Draw(o.w)
+	}
+	n, err := o.w.Write(gutter)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := b.WriteTo(o.w)
+	return int64(n) + m, err
+}
+
+// hashString is the FNV-1a hash of s.
+func hashString(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+	return h
+}
@@ -0,0 +1,42 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DeadlineKey is the key of the attribute added by AddDeadline.
+const DeadlineKey = "deadline"
+
+// remaining is the time remaining before a context deadline.
+type remaining time.Duration
+
+func (r remaining) String() string {
+	return time.Duration(r).String()
+}
+
+// withDeadline returns rec with a DeadlineKey attribute holding the time
+// remaining before the deadline of ctx, if any.
+func withDeadline(ctx context.Context, rec slog.Record) slog.Record {
+	if ctx == nil {
+		return rec
+	}
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return rec
+	}
+	rec = rec.Clone()
+	rec.AddAttrs(slog.Any(DeadlineKey, remaining(time.Until(dl).Round(time.Millisecond))))
+	return rec
+}
+
+// writeRemaining writes r dimmed, or styled as an error once
+// the deadline is exceeded.
+func (e *encoder) writeRemaining(buf *buffer, r remaining) {
+	style := e.opts.Theme.Timestamp()
+	if r <= 0 {
+		style = e.opts.Theme.AttrValueError()
+	}
+	e.writeColoredDuration(buf, time.Duration(r), style)
+}
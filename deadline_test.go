@@ -0,0 +1,29 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestHandler_AddDeadline(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, AddDeadline: true}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	logger.InfoContext(ctx, "with deadline", "k", "v")
+	logger.InfoContext(context.Background(), "without deadline")
+	expected := regexp.MustCompile(`^INF with deadline k=v deadline=(?:5\d\.\d+s|1m0s)\nINF without deadline\n$`)
+	if !expected.MatchString(buf.String()) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+
+	theme := NewDefaultTheme()
+	enc := newEncoder(HandlerOptions{Theme: theme}, nil)
+	b := new(buffer)
+	enc.writeRemaining(b, remaining(-time.Second))
+	AssertEqual(t, string(theme.AttrValueError())+"-1s"+string(ResetMod), b.String())
+}
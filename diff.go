@@ -0,0 +1,40 @@
+package console
+
+import "strings"
+
+// colorDiffs reports whether multiline values looking like unified diffs
+// are colorized line by line.
+func (e *encoder) colorDiffs() bool {
+	return !e.opts.NoColor
+}
+
+// isDiff reports whether s looks like a unified diff: it spans several
+// lines, and has either a hunk header or a pair of file headers.
+func isDiff(s string) bool {
+	if !strings.Contains(s, "\n") {
+		return false
+	}
+	return strings.HasPrefix(s, "@@ ") || strings.Contains(s, "\n@@ ") ||
+		(strings.HasPrefix(s, "--- ") || strings.Contains(s, "\n--- ")) && strings.Contains(s, "\n+++ ")
+}
+
+// writeDiff writes the unified diff s, styling added lines with
+// Theme.DiffAdded and removed lines with Theme.DiffRemoved.
+func (e *encoder) writeDiff(buf *buffer, s string) {
+	for len(s) > 0 {
+		line, rest, found := strings.Cut(s, "\n")
+		style := e.opts.Theme.AttrValue()
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		case strings.HasPrefix(line, "+"):
+			style = diffAddedStyle(e.opts.Theme)
+		case strings.HasPrefix(line, "-"):
+			style = diffRemovedStyle(e.opts.Theme)
+		}
+		e.writeColoredString(buf, line, style)
+		if found {
+			buf.AppendByte('\n')
+		}
+		s = rest
+	}
+}
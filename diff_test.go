@@ -0,0 +1,49 @@
+package console
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsDiff(t *testing.T) {
+	AssertEqual(t, true, isDiff("@@ -1 +1 @@\n-a\n+b"))
+	AssertEqual(t, true, isDiff("--- a.txt\n+++ b.txt\n-a\n+b"))
+	AssertEqual(t, true, isDiff("header\n@@ -1 +1 @@\n-a\n+b"))
+	AssertEqual(t, false, isDiff("-a\n+b"))
+	AssertEqual(t, false, isDiff("- item\n- other item"))
+	AssertEqual(t, false, isDiff("@@ -1 +1 @@"))
+}
+
+func TestHandler_DiffValue(t *testing.T) {
+	diff := "--- a\n+++ b\n@@ -1 +1 @@\n-old\n+new\n same"
+	theme := NewDefaultTheme()
+	val := string(ResetMod)
+	c := func(s string, m ANSIMod) string {
+		if m == "" {
+			return s
+		}
+		return string(m) + s + val
+	}
+	expected := c("--- a", theme.AttrValue()) + "\n" + c("+++ b", theme.AttrValue()) + "\n" +
+		c("@@ -1 +1 @@", theme.AttrValue()) + "\n" + c("-old", diffRemovedStyle(theme)) + "\n" +
+		c("+new", diffAddedStyle(theme)) + "\n" + c(" same", theme.AttrValue())
+
+	buf := new(buffer)
+	enc := newEncoder(HandlerOptions{Theme: theme}, nil)
+	enc.writeDiff(buf, diff)
+	AssertEqual(t, expected, buf.String())
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("diff", diff)
+	AssertEqual(t, "INF msg diff="+diff+"\n", string(Render(rec, &HandlerOptions{NoColor: true})))
+	out := string(Render(rec, &HandlerOptions{Theme: theme}))
+	AssertEqual(t, true, strings.HasSuffix(out, expected+"\n"))
+
+	// Truncation keeps the styles, and doesn't count them
+	truncated := c("--- a", theme.AttrValue()) + "\n" + c("+++ b", theme.AttrValue()) + "\n" +
+		string(theme.AttrValue()) + "@@ " + val + "...[truncated 24 bytes]"
+	out = string(Render(rec, &HandlerOptions{Theme: theme, MaxValueLength: 15}))
+	AssertEqual(t, true, strings.HasSuffix(out, truncated+"\n"))
+}
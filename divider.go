@@ -0,0 +1,95 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultWidth is the terminal width assumed when it's neither set in
+// HandlerOptions.Width nor in the COLUMNS environment variable.
+const DefaultWidth = 80
+
+const dividerRune = "─"
+
+// width returns the width of the terminal output is written to.
+func (o *HandlerOptions) width() int {
+	if o.Width > 0 {
+		return o.Width
+	}
+	if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 0 {
+		return c
+	}
+	return DefaultWidth
+}
+
+// Divider writes a horizontal rule spanning the terminal width, with an
+// optional title, like "──── phase: build ─────────". It's useful to
+// structure the output of long CLI runs. Nothing is written if the
+// handler is disabled. With HandlerOptions.Plain, the title is logged
+// as the message of a record at info level instead, if not empty.
+func (h *Handler) Divider(title string) error {
+	if h.disabled() {
+		return nil
+	}
+	if h.opts.Plain {
+		if title == "" {
+			return nil
+		}
+		return h.logPlain(title, nil)
+	}
+	buf := h.pool.get()
+	enc, _, _ := h.rendering()
+	enc.writeDivider(buf, title, h.opts.width())
+	_, err := buf.WriteTo(h.out)
+	h.releaseBuffer(buf)
+	return err
+}
+
+// logPlain logs msg and attrs in a record at info level, in place of the
+// decorations which are not written with HandlerOptions.Plain.
+func (h *Handler) logPlain(msg string, attrs []slog.Attr) error {
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.LevelInfo) {
+		return nil
+	}
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)
+	rec.AddAttrs(attrs...)
+	return h.Handle(ctx, rec)
+}
+
+// Divider writes a divider with the handler of logger, if it's a *Handler.
+// See Handler.Divider.
+func Divider(logger *slog.Logger, title string) {
+	if h, ok := logger.Handler().(*Handler); ok {
+		_ = h.Divider(title)
+	}
+}
+
+func (e *encoder) writeDivider(buf *buffer, title string, width int) {
+	style := e.opts.Theme.Timestamp()
+	rule := func(n int) {
+		e.withColor(buf, style, func() {
+			for i := 0; i < n; i++ {
+				buf.AppendString(dividerRune)
+			}
+		})
+	}
+	if title == "" {
+		rule(width)
+		e.NewLine(buf)
+		return
+	}
+	const lead = 4
+	rule(lead)
+	buf.AppendByte(' ')
+	e.writeColoredString(buf, title, e.opts.Theme.Message())
+	buf.AppendByte(' ')
+	if rest := width - lead - 2 - utf8.RuneCountInString(title); rest > 0 {
+		rule(rest)
+	}
+	e.NewLine(buf)
+}
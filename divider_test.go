@@ -0,0 +1,40 @@
+package console
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDivider(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, Width: 20})
+	AssertNoError(t, h.Divider("build"))
+	AssertNoError(t, h.Divider(""))
+	AssertNoError(t, h.Divider("a very long title overflowing"))
+	AssertEqual(t, "──── build ─────────\n"+strings.Repeat("─", 20)+"\n──── a very long title overflowing \n", buf.String())
+
+	buf.Reset()
+	h = NewHandler(&buf, &HandlerOptions{Width: 10})
+	Divider(slog.New(h), "x")
+	theme := h.Options().Theme
+	rule := func(n int) string { return string(theme.Timestamp()) + strings.Repeat("─", n) + string(ResetMod) }
+	AssertEqual(t, rule(4)+" "+string(theme.Message())+"x"+string(ResetMod)+" "+rule(3)+"\n", buf.String())
+}
+
+func TestHandlerOptions_Width(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	AssertEqual(t, DefaultWidth, (&HandlerOptions{}).width())
+	t.Setenv("COLUMNS", "132")
+	AssertEqual(t, 132, (&HandlerOptions{}).width())
+	AssertEqual(t, 40, (&HandlerOptions{Width: 40}).width())
+}
+
+func TestDivider_Plain(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{Plain: true, TimeFormat: "-"})
+	AssertNoError(t, h.Divider("build"))
+	AssertNoError(t, h.Divider(""))
+	AssertEqual(t, "- INF build\n", buf.String())
+}
@@ -1,6 +1,9 @@
 package console
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // appendDuration appends a string representing the duration in the form "72h3m0.5s".
 // Leading zero units are omitted. As a special case, durations less than one
@@ -124,3 +127,38 @@ func fmtInt(buf []byte, v uint64) int {
 	}
 	return w
 }
+
+// appendRelativeTime appends a human readable representation of the time t
+// relative to now, using the largest whole unit, like "3m ago" or "in 2h".
+// Times less than a second away format as "now".
+func appendRelativeTime(dst []byte, t, now time.Time) []byte {
+	d := t.Sub(now)
+	future := d > 0
+	if !future {
+		d = -d
+	}
+	if d < time.Second {
+		return append(dst, "now"...)
+	}
+	if future {
+		dst = append(dst, "in "...)
+	}
+	var n int64
+	var unit byte
+	switch {
+	case d < time.Minute:
+		n, unit = int64(d/time.Second), 's'
+	case d < time.Hour:
+		n, unit = int64(d/time.Minute), 'm'
+	case d < 24*time.Hour:
+		n, unit = int64(d/time.Hour), 'h'
+	default:
+		n, unit = int64(d/(24*time.Hour)), 'd'
+	}
+	dst = strconv.AppendInt(dst, n, 10)
+	dst = append(dst, unit)
+	if !future {
+		dst = append(dst, " ago"...)
+	}
+	return dst
+}
@@ -52,3 +52,21 @@ func BenchmarkDuration(b *testing.B) {
 		}
 	})
 }
+
+func TestAppendRelativeTime(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		d        time.Duration
+		expected string
+	}{
+		{0, "now"},
+		{-500 * time.Millisecond, "now"},
+		{-45 * time.Second, "45s ago"},
+		{-3*time.Minute - 10*time.Second, "3m ago"},
+		{2*time.Hour + 59*time.Minute, "in 2h"},
+		{-50 * time.Hour, "2d ago"},
+		{90 * time.Second, "in 1m"},
+	} {
+		AssertEqual(t, tc.expected, string(appendRelativeTime(nil, now.Add(tc.d), now)))
+	}
+}
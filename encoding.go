@@ -1,22 +1,137 @@
 package console
 
 import (
+	"bytes"
 	"fmt"
 	"log/slog"
-	"path/filepath"
-	"runtime"
 	"time"
+	"unicode/utf8"
 )
 
 type encoder struct {
-	opts HandlerOptions
+	opts    HandlerOptions
+	cols    *headerColumns // Widths of the header columns, if adaptive
+	changes *changeTracker // Previous values of attributes, if highlighted
+	errors  *errorCounter  // Occurrences of errors, if counted
+	values  *valueColumns  // Widths of the attribute values, if aligned
+
+	levelNames  map[slog.Level]string  // LevelNames, by level
+	levelStyles map[slog.Level]ANSIMod // LevelStyles, by level
+
+	// Optional styles of the theme, resolved once
+	punct     ANSIMod
+	attrGroup ANSIMod
+	// formatValues is set if values may be formatted after their key
+	formatValues bool
+	// pad is the padding of the last value with AlignValues, written
+	// only if another attribute follows it. If nil, values are padded
+	// right away.
+	pad *int
+}
+
+// newEncoder creates an encoder for opts. The state shared between
+// records, like the header column widths, is taken over from prev
+// if it's not nil.
+func newEncoder(opts HandlerOptions, prev *encoder) *encoder {
+	e := &encoder{opts: opts}
+	if prev != nil {
+		e.cols, e.changes, e.errors, e.values = prev.cols, prev.changes, prev.errors, prev.values
+	}
+	if opts.AdaptiveHeaders && e.cols == nil {
+		e.cols = new(headerColumns)
+	}
+	if opts.HighlightChanges && e.changes == nil {
+		e.changes = newChangeTracker()
+	}
+	if opts.CountErrors && e.errors == nil {
+		e.errors = newErrorCounter()
+	}
+	if opts.AlignValues && e.values == nil {
+		e.values = newValueColumns()
+	}
+	e.levelNames = byLevel(opts.LevelNames)
+	e.levelStyles = byLevel(opts.LevelStyles)
+	e.setTheme(opts.Theme)
+	e.formatValues = opts.FormatValue != nil || opts.HumanizeNumbers || len(opts.HumanizeKeys) > 0 ||
+		len(opts.GroupDigitsKeys) > 0 || opts.Units != nil
+	return e
+}
+
+// setTheme sets the theme of e, and resolves its optional styles.
+func (e *encoder) setTheme(t Theme) {
+	e.opts.Theme = t
+	if t != nil {
+		e.punct = punctuationStyle(t)
+		e.attrGroup = attrGroupStyle(t)
+	}
 }
 
-func (e encoder) NewLine(buf *buffer) {
+func (e *encoder) NewLine(buf *buffer) {
 	buf.AppendByte('\n')
 }
 
-func (e encoder) withColor(b *buffer, c ANSIMod, f func()) {
+// writeRecord writes the whole line for rec into buf. The pre-rendered
+// context attributes are inserted before the record's own attributes,
+// which belong to the groups g.
+func (e *encoder) writeRecord(buf *buffer, rec slog.Record, context *buffer, g groups) {
+	if e.opts.AlignValues {
+		// Declared here to only be allocated when aligning values
+		pad := g.pad
+		var pe encoder
+		e = e.withPad(&pad, &pe)
+	}
+	start := buf.Len()
+	if !e.opts.HideTime {
+		e.writeTimestamp(buf, rec.Time)
+		if e.opts.Verbose {
+			e.writeElapsed(buf, rec.Time)
+		}
+		start = e.padColumn(buf, colTimestamp, start)
+	}
+	if !e.opts.HideLevel {
+		e.writeLevel(buf, rec.Level)
+		start = e.padColumn(buf, colLevel, start)
+	}
+	if e.opts.Verbose {
+		e.writeGoroutineID(buf)
+	}
+	if e.opts.AddSource && rec.PC > 0 && !e.opts.SourceAsAttr {
+		e.writeSource(buf, rec.PC, cwd)
+	}
+	e.padColumn(buf, colSource, start)
+	for i := 0; i < e.opts.Indent; i++ {
+		buf.AppendString("  ")
+	}
+	e.writeMessage(buf, rec.Level, rec.Message)
+	if e.opts.HideAttrs {
+		e.NewLine(buf)
+		return
+	}
+	if context != nil {
+		buf.copy(context)
+	}
+	if e.opts.HighlightChanges {
+		e.writeChangedAttrs(buf, rec, &g)
+	} else {
+		rec.Attrs(func(a slog.Attr) bool {
+			e.writeGroupedAttr(buf, a, &g)
+			return true
+		})
+	}
+	e.closeGroups(buf, g.opened)
+	if e.opts.AddSource && rec.PC > 0 && e.opts.SourceAsAttr {
+		e.writeSourceAttr(buf, rec.PC, cwd)
+	}
+	if e.opts.AddUptime {
+		e.writeUptime(buf, rec.Time)
+	}
+	if e.opts.CountErrors && e.errors != nil && rec.Level >= slog.LevelError {
+		e.writeErrorCount(buf, rec, context)
+	}
+	e.NewLine(buf)
+}
+
+func (e *encoder) withColor(b *buffer, c ANSIMod, f func()) {
 	if c == "" || e.opts.NoColor {
 		f()
 		return
@@ -26,71 +141,138 @@ func (e encoder) withColor(b *buffer, c ANSIMod, f func()) {
 	b.AppendString(string(ResetMod))
 }
 
-func (e encoder) writeColoredTime(w *buffer, t time.Time, format string, c ANSIMod) {
+// writePunct writes the structural character c, like '=' or '{',
+// styled with Theme.Punctuation.
+func (e *encoder) writePunct(buf *buffer, c byte) {
+	e.withColor(buf, e.punct, func() {
+		buf.AppendByte(c)
+	})
+}
+
+// writeGroupPrefix writes the group prefix of an attribute key, like
+// "group.sub.", with the names styled with Theme.AttrGroup and the dots
+// with Theme.Punctuation.
+func (e *encoder) writeGroupPrefix(buf *buffer, prefix []byte) {
+	if e.punct == "" {
+		e.withColor(buf, e.attrGroup, func() {
+			buf.Append(prefix)
+		})
+		return
+	}
+	for len(prefix) > 0 {
+		i := bytes.IndexByte(prefix, '.')
+		if i < 0 {
+			i = len(prefix)
+		}
+		e.withColor(buf, e.attrGroup, func() {
+			buf.Append(prefix[:i])
+		})
+		if i < len(prefix) {
+			e.writePunct(buf, '.')
+			i++
+		}
+		prefix = prefix[i:]
+	}
+}
+
+func (e *encoder) writeColoredTime(w *buffer, t time.Time, format string, c ANSIMod) {
 	e.withColor(w, c, func() {
 		w.AppendTime(t, format)
 	})
 }
 
-func (e encoder) writeColoredString(w *buffer, s string, c ANSIMod) {
+func (e *encoder) writeColoredString(w *buffer, s string, c ANSIMod) {
+	if e.opts.Plain {
+		s = escapeNewlines(s)
+	}
 	e.withColor(w, c, func() {
 		w.AppendString(s)
 	})
 }
 
-func (e encoder) writeColoredInt(w *buffer, i int64, c ANSIMod) {
+func (e *encoder) writeColoredInt(w *buffer, i int64, c ANSIMod) {
 	e.withColor(w, c, func() {
 		w.AppendInt(i)
 	})
 }
 
-func (e encoder) writeColoredUint(w *buffer, i uint64, c ANSIMod) {
+func (e *encoder) writeColoredUint(w *buffer, i uint64, c ANSIMod) {
 	e.withColor(w, c, func() {
 		w.AppendUint(i)
 	})
 }
 
-func (e encoder) writeColoredFloat(w *buffer, i float64, c ANSIMod) {
+func (e *encoder) writeColoredFloat(w *buffer, i float64, c ANSIMod) {
 	e.withColor(w, c, func() {
-		w.AppendFloat(i)
+		*w = e.opts.FloatFormat.append(*w, i)
 	})
 }
 
-func (e encoder) writeColoredBool(w *buffer, b bool, c ANSIMod) {
+func (e *encoder) writeColoredBool(w *buffer, b bool, c ANSIMod) {
 	e.withColor(w, c, func() {
 		w.AppendBool(b)
 	})
 }
 
-func (e encoder) writeColoredDuration(w *buffer, d time.Duration, c ANSIMod) {
+func (e *encoder) writeColoredDuration(w *buffer, d time.Duration, c ANSIMod) {
 	e.withColor(w, c, func() {
 		w.AppendDuration(d)
 	})
 }
 
-func (e encoder) writeTimestamp(buf *buffer, tt time.Time) {
-	if !tt.IsZero() {
+func (e *encoder) writeTimestamp(buf *buffer, tt time.Time) {
+	if tt.IsZero() {
+		return
+	}
+	if e.opts.EncodeTimestamp != nil {
+		e.withColor(buf, e.opts.Theme.Timestamp(), func() {
+			e.opts.EncodeTimestamp((*Buffer)(buf), tt)
+		})
+	} else {
 		e.writeColoredTime(buf, tt, e.opts.TimeFormat, e.opts.Theme.Timestamp())
-		buf.AppendByte(' ')
 	}
+	buf.AppendByte(' ')
 }
 
-func (e encoder) writeSource(buf *buffer, pc uintptr, cwd string) {
-	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
-	if cwd != "" {
-		if ff, err := filepath.Rel(cwd, frame.File); err == nil {
-			frame.File = ff
-		}
-	}
+func (e *encoder) writeSource(buf *buffer, pc uintptr, cwd string) {
+	e.writeSourcePos(buf, pc, cwd)
+	e.writeColoredString(buf, " > ", sourceSeparatorStyle(e.opts.Theme))
+}
+
+// writeSourceAttr writes the source code position of pc as a trailing
+// attribute with key slog.SourceKey, preceded by a separator.
+func (e *encoder) writeSourceAttr(buf *buffer, pc uintptr, cwd string) {
+	buf.AppendString(e.opts.AttrSeparator)
+	e.withColor(buf, e.opts.Theme.AttrKey(), func() {
+		buf.AppendString(slog.SourceKey)
+	})
+	e.writeColoredString(buf, e.opts.KeyValueSeparator, e.punct)
+	e.writeSourcePos(buf, pc, cwd)
+}
+
+// writeSourcePos writes the source code position of pc, styled with Theme.Source.
+func (e *encoder) writeSourcePos(buf *buffer, pc uintptr, cwd string) {
+	src := sources.get(pc, cwd)
 	e.withColor(buf, e.opts.Theme.Source(), func() {
-		buf.AppendString(frame.File)
+		if e.opts.EncodeSource != nil {
+			e.opts.EncodeSource((*Buffer)(buf), src.frame)
+			return
+		}
+		if e.opts.Verbose {
+			buf.AppendString(src.frame.File)
+		} else {
+			buf.AppendString(src.file)
+		}
 		buf.AppendByte(':')
-		buf.AppendInt(int64(frame.Line))
+		buf.AppendInt(int64(src.frame.Line))
+		if e.opts.Verbose && src.frame.Function != "" {
+			buf.AppendByte(' ')
+			buf.AppendString(src.frame.Function)
+		}
 	})
-	e.writeColoredString(buf, " > ", e.opts.Theme.AttrKey())
 }
 
-func (e encoder) writeMessage(buf *buffer, level slog.Level, msg string) {
+func (e *encoder) writeMessage(buf *buffer, level slog.Level, msg string) {
 	if level >= slog.LevelInfo {
 		e.writeColoredString(buf, msg, e.opts.Theme.Message())
 	} else {
@@ -98,35 +280,196 @@ func (e encoder) writeMessage(buf *buffer, level slog.Level, msg string) {
 	}
 }
 
-func (e encoder) writeAttr(buf *buffer, a slog.Attr, group string) {
+// writeAttr writes a, preceded by a space, with its key prefixed by prefix.
+// The prefix is either empty or made of the dot terminated names of the
+// enclosing groups, like "group.subgroup.".
+func (e *encoder) writeAttr(buf *buffer, a slog.Attr, prefix []byte) {
+	e.writeAttrSep(buf, a, prefix, true)
+}
+
+// writeAttrSep is like writeAttr, but only writes the leading space if sep
+// is true. It reports whether something was written.
+func (e *encoder) writeAttrSep(buf *buffer, a slog.Attr, prefix []byte, sep bool) bool {
 	// Elide empty Attrs.
-	if a.Equal(slog.Attr{}) {
-		return
+	if a.Key == "" && a.Value.Equal(slog.Value{}) {
+		return false
 	}
 	value := a.Value.Resolve()
+	if r := e.opts.Redact; r != nil {
+		if r.Matches(a.Key) {
+			value = slog.StringValue(r.Mask(value))
+		} else if r.DetectSecrets {
+			if text, ok := textValue(value); ok {
+				if s, found := r.scan(a.Key, text); found {
+					value = slog.StringValue(s)
+				}
+			}
+		}
+	}
 	if value.Kind() == slog.KindGroup {
-		subgroup := a.Key
-		if group != "" {
-			subgroup = group + "." + a.Key
+		if e.opts.GroupThemes != nil && a.Key != "" {
+			var ge encoder
+			e = e.forGroup(a.Key, &ge)
+		}
+		if e.opts.NestedGroups && a.Key != "" {
+			return e.writeNestedGroup(buf, a.Key, value.Group(), sep)
+		}
+		subprefix := prefix
+		// Inline groups with an empty key
+		if a.Key != "" {
+			// Use a stack allocated array to build the prefix in the common case
+			var scratch [64]byte
+			subprefix = appendGroupPrefix(append(scratch[:0], prefix...), a.Key)
 		}
+		written := false
 		for _, attr := range value.Group() {
-			e.writeAttr(buf, attr, subgroup)
+			if e.writeAttrSep(buf, attr, subprefix, sep || written) {
+				written = true
+			}
 		}
-		return
+		return written
 	}
-	buf.AppendByte(' ')
+	if sep {
+		e.writePad(buf)
+		buf.AppendString(e.opts.AttrSeparator)
+	} else {
+		e.dropPad()
+	}
+	if len(prefix) > 0 && !e.opts.HideGroupPrefix && e.opts.EncodeKey == nil {
+		e.writeGroupPrefix(buf, prefix)
+	}
+	bare := e.opts.BareTrueBools && value.Kind() == slog.KindBool && value.Bool()
+	// Without a punctuation style, '=' is styled as the key
+	punct := e.punct != ""
 	e.withColor(buf, e.opts.Theme.AttrKey(), func() {
-		if group != "" {
-			buf.AppendString(group)
-			buf.AppendByte('.')
+		if e.opts.EncodeKey != nil {
+			e.opts.EncodeKey((*Buffer)(buf), string(bytes.TrimSuffix(prefix, []byte{'.'})), a.Key)
+		} else {
+			buf.AppendString(a.Key)
+		}
+		if !bare && !punct {
+			buf.AppendString(e.opts.KeyValueSeparator)
 		}
-		buf.AppendString(a.Key)
-		buf.AppendByte('=')
 	})
-	e.writeValue(buf, value)
+	if bare {
+		return true
+	}
+	if punct {
+		e.writeColoredString(buf, e.opts.KeyValueSeparator, e.punct)
+	}
+	start := buf.Len()
+	var truncated bool
+	if style, ok := e.opts.KeyStyles[a.Key]; ok || e.opts.NoValueColor {
+		ve := *e
+		ve.opts.NoColor = ve.opts.NoColor || e.opts.NoValueColor
+		if ok {
+			ve.opts.Theme = keyTheme{wrappedTheme{e.opts.Theme}, style}
+		}
+		truncated = ve.writeAttrValue(buf, a.Key, value)
+	} else {
+		truncated = e.writeAttrValue(buf, a.Key, value)
+	}
+	if e.opts.MaxValueLength > 0 && !truncated {
+		e.truncateValue(buf, start)
+	}
+	e.padValue(buf, prefix, a.Key, start)
+	return true
+}
+
+// keyTheme is a theme whose values are styled with the style
+// associated with their key in KeyStyles.
+type keyTheme struct {
+	wrappedTheme
+	style ANSIMod
+}
+
+func (t keyTheme) AttrValue() ANSIMod { return t.style }
+
+// truncateValue truncates the value written in buf from start if its
+// visible text is longer than MaxValueLength, and appends a truncation
+// notice. The escape sequences styling the value are not counted, and
+// are never cut through.
+func (e *encoder) truncateValue(buf *buffer, start int) {
+	val := (*buf)[start:]
+	cut, kept, n := -1, 0, 0 // Where to cut, visible length before the cut, and in total
+	colored := false
+	for i := 0; i < len(val); {
+		if l := csiLen(val[i:]); l > 0 {
+			colored = true
+			i += l
+			continue
+		}
+		_, size := utf8.DecodeRune(val[i:])
+		if cut < 0 && n+size > e.opts.MaxValueLength {
+			cut, kept = i, n
+		}
+		n += size
+		i += size
+	}
+	if cut < 0 {
+		return
+	}
+	dropped := n - kept
+	*buf = (*buf)[:start+cut]
+	if colored {
+		buf.AppendString(string(ResetMod))
+	}
+	appendTruncated(buf, dropped)
 }
 
-func (e encoder) writeValue(buf *buffer, value slog.Value) {
+// appendTruncated appends the notice following a truncated value.
+func appendTruncated(buf *buffer, dropped int) {
+	buf.AppendString("...[truncated ")
+	buf.AppendInt(int64(dropped))
+	buf.AppendString(" bytes]")
+}
+
+// writeTextValue writes the value if it's rendered as plain text: a
+// string, an error or a fmt.Stringer. That text is truncated to
+// MaxValueLength before being written, so that a large value doesn't
+// grow the buffer. It reports whether the value was written.
+func (e *encoder) writeTextValue(buf *buffer, value slog.Value) bool {
+	style := e.opts.Theme.AttrValue()
+	switch value.Kind() {
+	case slog.KindString:
+		if e.colorDiffs() && isDiff(value.String()) {
+			return false
+		}
+	case slog.KindAny:
+		switch value.Any().(type) {
+		case Elapsed, remaining, Percentage, ByteSize:
+			return false
+		case error:
+			style = e.opts.Theme.AttrValueError()
+		}
+	default:
+		return false
+	}
+	s, ok := textValue(value)
+	if !ok {
+		return false
+	}
+	max := e.opts.MaxValueLength
+	if len(s) <= max {
+		e.writeColoredString(buf, s, style)
+		return true
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	e.writeColoredString(buf, s[:cut], style)
+	appendTruncated(buf, len(s)-cut)
+	return true
+}
+
+// appendGroupPrefix appends the group name to the key prefix dst.
+func appendGroupPrefix(dst []byte, name string) []byte {
+	dst = append(dst, name...)
+	return append(dst, '.')
+}
+
+func (e *encoder) writeValue(buf *buffer, value slog.Value) {
 	attrValue := e.opts.Theme.AttrValue()
 	switch value.Kind() {
 	case slog.KindInt64:
@@ -136,57 +479,263 @@ func (e encoder) writeValue(buf *buffer, value slog.Value) {
 	case slog.KindFloat64:
 		e.writeColoredFloat(buf, value.Float64(), attrValue)
 	case slog.KindTime:
-		e.writeColoredTime(buf, value.Time(), e.opts.TimeFormat, attrValue)
+		if e.opts.RelativeTimes {
+			e.withColor(buf, attrValue, func() {
+				*buf = appendRelativeTime(*buf, value.Time(), time.Now())
+			})
+		} else {
+			e.writeColoredTime(buf, value.Time(), e.opts.TimeFormat, attrValue)
+		}
 	case slog.KindUint64:
 		e.writeColoredUint(buf, value.Uint64(), attrValue)
 	case slog.KindDuration:
 		e.writeColoredDuration(buf, value.Duration(), attrValue)
 	case slog.KindAny:
-		switch v := value.Any().(type) {
-		case error:
-			e.writeColoredString(buf, v.Error(), e.opts.Theme.AttrValueError())
-			return
-		case fmt.Stringer:
-			e.writeColoredString(buf, v.String(), attrValue)
-			return
-		}
-		fallthrough
+		e.writeAnyValue(buf, value, attrValue)
 	case slog.KindString:
-		fallthrough
+		if s := value.String(); e.colorDiffs() && isDiff(s) {
+			e.writeDiff(buf, s)
+		} else {
+			e.writeColoredString(buf, s, attrValue)
+		}
+	default:
+		e.writeFallbackValue(buf, value, attrValue)
+	}
+}
+
+// writeAnyValue writes the value of kind slog.KindAny, styled with attrValue
+// unless it has a dedicated style.
+func (e *encoder) writeAnyValue(buf *buffer, value slog.Value, attrValue ANSIMod) {
+	// Stringer, error and fallback implementations may panic. Don't let
+	// that crash the application, replace the value with a marker instead.
+	start := buf.Len()
+	defer func() {
+		if r := recover(); r != nil {
+			e.writePanic(buf, start, r)
+		}
+	}()
+	switch v := value.Any().(type) {
+	case Elapsed:
+		e.writeColoredDuration(buf, time.Duration(v), e.elapsedStyle(v))
+		return
+	case remaining:
+		e.writeRemaining(buf, v)
+		return
+	case Percentage:
+		e.withColor(buf, e.percentStyle(v), func() {
+			*buf = v.append(*buf)
+		})
+		return
+	case ByteSize:
+		e.withColor(buf, attrValue, func() {
+			*buf = appendByteSize(*buf, v)
+		})
+		return
+	case error:
+		e.writeColoredString(buf, v.Error(), e.opts.Theme.AttrValueError())
+		return
+	case fmt.Stringer:
+		e.writeColoredString(buf, v.String(), attrValue)
+		return
+	}
+	e.writeFallbackValue(buf, value, attrValue)
+}
+
+// writeAttrValue writes the value of the attribute key, using the
+// formatting options depending on the key, if any. It reports whether
+// the value was truncated to MaxValueLength already.
+func (e *encoder) writeAttrValue(buf *buffer, key string, value slog.Value) bool {
+	if !e.formatValues {
+		if e.opts.MaxValueLength > 0 && e.writeTextValue(buf, value) {
+			return true
+		}
+		e.writeValue(buf, value)
+		return false
+	}
+	switch {
+	case e.writeFormattedValue(buf, key, value):
+		return false
+	case e.writeHumanizedNumber(buf, key, value):
+	case e.writeGroupedDigits(buf, key, value):
+	default:
+		e.writeValue(buf, value)
+	}
+	e.writeUnit(buf, key, value)
+	return false
+}
+
+// writeFormattedValue writes the value of the attribute key as rendered
+// by the FormatValue hook, if any. It reports whether the hook handled it.
+func (e *encoder) writeFormattedValue(buf *buffer, key string, value slog.Value) (handled bool) {
+	if e.opts.FormatValue == nil || value.Kind() == slog.KindGroup {
+		return false
+	}
+	start := buf.Len()
+	defer func() {
+		if r := recover(); r != nil {
+			e.writePanic(buf, start, r)
+			handled = true
+		}
+	}()
+	s, ok := e.opts.FormatValue(key, value)
+	if ok {
+		e.writeColoredString(buf, s, e.opts.Theme.AttrValue())
+	}
+	return ok
+}
+
+// writePanic replaces what was written in buf from start
+// with a marker for the panic r.
+func (e *encoder) writePanic(buf *buffer, start int, r any) {
+	*buf = (*buf)[:start]
+	e.writeColoredString(buf, "!PANIC formatting value: "+panicString(r), e.opts.Theme.AttrValueError())
+}
+
+// panicString returns the text of the recovered panic r, using fmt
+// only for values of other types than the usual string and error.
+func panicString(r any) string {
+	switch r := r.(type) {
+	case string:
+		return r
+	case error:
+		return r.Error()
 	default:
-		e.writeColoredString(buf, value.String(), attrValue)
+		return fmt.Sprint(r)
+	}
+}
+
+func (e *encoder) writeFallbackValue(buf *buffer, value slog.Value, c ANSIMod) {
+	if e.opts.EncodeFallback != nil {
+		start := buf.Len()
+		handled := false
+		e.withColor(buf, c, func() {
+			handled = e.opts.EncodeFallback((*Buffer)(buf), value)
+		})
+		if handled {
+			return
+		}
+		*buf = (*buf)[:start]
 	}
+	e.writeColoredString(buf, value.String(), c)
 }
 
-func (e encoder) writeLevel(buf *buffer, l slog.Level) {
-	var style ANSIMod
+func (e *encoder) writeLevel(buf *buffer, l slog.Level) {
 	var str string
 	var delta int
 	switch {
 	case l >= slog.LevelError:
-		style = e.opts.Theme.LevelError()
 		str = "ERR"
 		delta = int(l - slog.LevelError)
 	case l >= slog.LevelWarn:
-		style = e.opts.Theme.LevelWarn()
 		str = "WRN"
 		delta = int(l - slog.LevelWarn)
 	case l >= slog.LevelInfo:
-		style = e.opts.Theme.LevelInfo()
 		str = "INF"
 		delta = int(l - slog.LevelInfo)
-	case l >= slog.LevelDebug:
-		style = e.opts.Theme.LevelDebug()
-		str = "DBG"
-		delta = int(l - slog.LevelDebug)
 	default:
-		style = e.opts.Theme.LevelDebug()
 		str = "DBG"
 		delta = int(l - slog.LevelDebug)
 	}
-	if delta != 0 {
-		str = fmt.Sprintf("%s%+d", str, delta)
+	numeric := e.opts.LevelDelta == LevelDeltaNumeric
+	if e.opts.LevelDelta == LevelDeltaHide {
+		delta = 0
+	}
+	if name, ok := e.levelNames[l]; ok {
+		str, delta, numeric = name, 0, false
+	}
+	style, ok := e.levelStyles[l]
+	if !ok {
+		style = e.opts.Theme.Level(l)
+	}
+	start := buf.Len()
+	if e.opts.EncodeLevel != nil {
+		e.withColor(buf, style, func() {
+			e.opts.EncodeLevel((*Buffer)(buf), l)
+		})
+	} else {
+		e.withColor(buf, style, func() {
+			if numeric {
+				buf.AppendInt(int64(l))
+				return
+			}
+			buf.AppendString(str)
+			if delta > 0 {
+				buf.AppendByte('+')
+			}
+			if delta != 0 {
+				buf.AppendInt(int64(delta))
+			}
+		})
+	}
+	if e.opts.LevelWidth > 0 {
+		for pad := e.opts.LevelWidth - visibleLen((*buf)[start:]); pad > 0; pad-- {
+			buf.AppendByte(' ')
+		}
 	}
-	e.writeColoredString(buf, str, style)
 	buf.AppendByte(' ')
 }
+
+// Encoder renders the individual parts of a log line into a Buffer,
+// using the same coloring and value formatting as a Handler. It can
+// be used to build custom layouts.
+type Encoder struct {
+	enc encoder
+}
+
+// NewEncoder creates an Encoder using the given options.
+// If opts is nil, the default options are used.
+func NewEncoder(opts *HandlerOptions) *Encoder {
+	if opts == nil {
+		opts = new(HandlerOptions)
+	}
+	o := *opts
+	o.setDefaults()
+	return &Encoder{enc: *newEncoder(o, nil)}
+}
+
+// WriteRecord writes the complete line for rec, as a Handler would.
+func (e *Encoder) WriteRecord(buf *Buffer, rec slog.Record) {
+	e.enc.writeRecord((*buffer)(buf), rec, nil, groups{})
+}
+
+// WriteTimestamp writes t followed by a space. Nothing is written if t is zero.
+func (e *Encoder) WriteTimestamp(buf *Buffer, t time.Time) {
+	e.enc.writeTimestamp((*buffer)(buf), t)
+}
+
+// WriteLevel writes the level label followed by a space.
+func (e *Encoder) WriteLevel(buf *Buffer, l slog.Level) {
+	e.enc.writeLevel((*buffer)(buf), l)
+}
+
+// WriteSource writes the source location of pc, relative to the
+// current working directory, followed by the " > " separator.
+func (e *Encoder) WriteSource(buf *Buffer, pc uintptr) {
+	e.enc.writeSource((*buffer)(buf), pc, cwd)
+}
+
+// WriteMessage writes msg styled according to level.
+func (e *Encoder) WriteMessage(buf *Buffer, level slog.Level, msg string) {
+	e.enc.writeMessage((*buffer)(buf), level, msg)
+}
+
+// WriteAttr writes a, preceded by a space. The key is qualified with
+// group if not empty. Groups are flattened into dotted keys, unless
+// NestedGroups is set.
+func (e *Encoder) WriteAttr(buf *Buffer, a slog.Attr, group string) {
+	var prefix []byte
+	if group != "" {
+		prefix = appendGroupPrefix(nil, group)
+	}
+	e.enc.writeAttr((*buffer)(buf), a, prefix)
+}
+
+// WriteValue writes the resolved value v.
+func (e *Encoder) WriteValue(buf *Buffer, v slog.Value) {
+	e.enc.writeValue((*buffer)(buf), v.Resolve())
+}
+
+// NewLine terminates the current line.
+func (e *Encoder) NewLine(buf *Buffer) {
+	e.enc.NewLine((*buffer)(buf))
+}
@@ -0,0 +1,47 @@
+package console
+
+import (
+	"strconv"
+	"time"
+)
+
+// Pseudo layouts for HandlerOptions.TimeFormat rendering times as Unix
+// epoch timestamps, for pipelines and scripts expecting them.
+const (
+	TimeFormatUnix      = "unix"      // Seconds since the epoch, like "1700000000"
+	TimeFormatUnixMilli = "unixmilli" // Milliseconds since the epoch
+	TimeFormatUnixNano  = "unixnano"  // Nanoseconds since the epoch
+)
+
+// appendTime appends t formatted with the layout format, or as an epoch
+// timestamp if format is one of the TimeFormatUnix pseudo layouts.
+func appendTime(dst []byte, t time.Time, format string) []byte {
+	switch format {
+	case TimeFormatUnix:
+		return strconv.AppendInt(dst, t.Unix(), 10)
+	case TimeFormatUnixMilli:
+		return strconv.AppendInt(dst, t.UnixMilli(), 10)
+	case TimeFormatUnixNano:
+		return strconv.AppendInt(dst, t.UnixNano(), 10)
+	}
+	return t.AppendFormat(dst, format)
+}
+
+// parseTime is the reverse of appendTime, in the local time zone.
+func parseTime(s, format string) (time.Time, error) {
+	switch format {
+	case TimeFormatUnix, TimeFormatUnixMilli, TimeFormatUnixNano:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		switch format {
+		case TimeFormatUnix:
+			return time.Unix(n, 0), nil
+		case TimeFormatUnixMilli:
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(0, n), nil
+	}
+	return time.ParseInLocation(format, s, time.Local)
+}
@@ -0,0 +1,34 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandler_TimeFormatUnix(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	for _, tc := range []struct {
+		format   string
+		expected string
+		parsed   time.Time
+	}{
+		{TimeFormatUnix, "1700000000", now.Truncate(time.Second)},
+		{TimeFormatUnixMilli, "1700000000123", now},
+		{TimeFormatUnixNano, "1700000000123000000", now},
+	} {
+		buf := bytes.Buffer{}
+		opts := &HandlerOptions{NoColor: true, TimeFormat: tc.format}
+		h := NewHandler(&buf, opts)
+		rec := slog.NewRecord(now, slog.LevelInfo, "msg", 0)
+		rec.Add("at", now)
+		AssertNoError(t, h.Handle(context.Background(), rec))
+		AssertEqual(t, tc.expected+" INF msg at="+tc.expected+"\n", buf.String())
+
+		m, err := ParseLine(buf.String(), opts)
+		AssertNoError(t, err)
+		AssertEqual(t, true, m[slog.TimeKey].(time.Time).Equal(tc.parsed))
+	}
+}
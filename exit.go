@@ -0,0 +1,35 @@
+package console
+
+import "context"
+
+// exit flushes the records buffered under ctx and the outputs of h, then
+// terminates the program with HandlerOptions.ExitFunc and code.
+func (h *Handler) exit(ctx context.Context, code int) {
+	_ = FlushRecords(ctx)
+	flushWriter(h.out)
+	for _, m := range h.mirrors {
+		flushWriter(m.out)
+	}
+	h.opts.ExitFunc(code)
+}
+
+// flushWriter flushes the writer of out, if it is buffered like a
+// *bufio.Writer, or commits it to stable storage if it is an *os.File.
+// A writer returned by AutoColor is flushed through. Errors are ignored,
+// as there is nobody left to report them to.
+func flushWriter(out *output) {
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	w := out.w
+	if p, ok := w.(*plainWriter); ok {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		w = p.w
+	}
+	if f, ok := w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if s, ok := w.(interface{ Sync() error }); ok {
+		_ = s.Sync()
+	}
+}
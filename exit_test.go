@@ -0,0 +1,68 @@
+package console
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestHandler_ExitLevel(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	code := -1
+	logger := slog.New(NewHandler(w, &HandlerOptions{
+		NoColor:   true,
+		HideTime:  true,
+		ExitLevel: slog.LevelWarn,
+		ExitFunc:  func(c int) { code = c },
+	}))
+
+	ctx := BufferRecords(context.Background())
+	logger.InfoContext(ctx, "buffered")
+	logger.Info("not exiting")
+	AssertEqual(t, -1, code)
+	AssertEqual(t, "", buf.String())
+
+	logger.WarnContext(ctx, "exiting")
+	AssertEqual(t, 1, code)
+	AssertEqual(t, "INF not exiting\n"+blockStart+"INF buffered\n"+blockEnd+"WRN exiting\n", buf.String())
+}
+
+func TestHandler_ExitLevelDisabled(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	code := -1
+	h := NewHandler(AutoColor(w), &HandlerOptions{
+		Level:     slog.LevelError + 4,
+		ExitLevel: slog.LevelError,
+		ExitFunc:  func(c int) { code = c },
+	})
+	AssertEqual(t, true, h.Enabled(context.Background(), slog.LevelError))
+	AssertEqual(t, false, h.Enabled(context.Background(), slog.LevelWarn))
+
+	slog.New(h).Warn("ignored")
+	AssertEqual(t, -1, code)
+	slog.New(h).Error("exiting")
+	AssertEqual(t, 1, code)
+
+	code = -1
+	logger := slog.New(NewHandler(AutoColor(w), &HandlerOptions{
+		HideTime:  true,
+		ExitLevel: slog.LevelError,
+		ExitFunc:  func(c int) { code = c },
+	}))
+	logger.Error("flushed")
+	AssertEqual(t, 1, code)
+	AssertEqual(t, "ERR flushed\n", buf.String())
+
+	code = -1
+	logger = slog.New(NewHandler(w, &HandlerOptions{
+		Disabled:  true,
+		ExitLevel: slog.LevelError,
+		ExitFunc:  func(c int) { code = c },
+	}))
+	logger.Error("disabled")
+	AssertEqual(t, 1, code)
+}
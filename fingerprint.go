@@ -0,0 +1,105 @@
+package console
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultErrorWindow is the window errors are counted over
+// when HandlerOptions.ErrorWindow is zero.
+const DefaultErrorWindow = time.Minute
+
+// errorCounter counts the occurrences of errors by fingerprint,
+// over fixed time windows.
+type errorCounter struct {
+	mu     sync.Mutex
+	counts map[string]*errorCount
+}
+
+type errorCount struct {
+	start time.Time // Start of the current window
+	n     int
+}
+
+func newErrorCounter() *errorCounter {
+	return &errorCounter{counts: make(map[string]*errorCount)}
+}
+
+// observe records an occurrence of the error with fingerprint fp at t, and
+// returns the number of occurrences in the window of length w it belongs to.
+func (c *errorCounter) observe(fp string, t time.Time, w time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ec, ok := c.counts[fp]
+	if !ok {
+		if len(c.counts) >= maxKeySets {
+			clear(c.counts)
+		}
+		ec = new(errorCount)
+		c.counts[fp] = ec
+	}
+	if ec.n == 0 || t.Sub(ec.start) >= w {
+		ec.start, ec.n = t, 0
+	}
+	ec.n++
+	return ec.n
+}
+
+// errorFingerprint identifies the error reported by rec, from its
+// message, the rendered context attributes of the handler and the errors
+// among its attributes, including in groups.
+func errorFingerprint(rec slog.Record, context *buffer) string {
+	var sb strings.Builder
+	sb.WriteString(rec.Message)
+	if context != nil {
+		sb.WriteByte(0)
+		sb.Write(*context)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		appendErrors(&sb, a.Value)
+		return true
+	})
+	return sb.String()
+}
+
+// appendErrors appends the messages of the errors held by v to sb.
+func appendErrors(sb *strings.Builder, v slog.Value) {
+	v = v.Resolve()
+	if v.Kind() == slog.KindGroup {
+		for _, a := range v.Group() {
+			appendErrors(sb, a.Value)
+		}
+		return
+	}
+	if _, ok := v.Any().(error); !ok {
+		return
+	}
+	// Errors whose method panics are left out, to be reported when written
+	if text, ok := textValue(v); ok {
+		sb.WriteByte(0)
+		sb.WriteString(text)
+	}
+}
+
+// writeErrorCount writes how many times the error reported by rec was seen
+// in the current window, like " seen 14x in 1m0s window", if more than once.
+func (e *encoder) writeErrorCount(buf *buffer, rec slog.Record, context *buffer) {
+	w := e.opts.ErrorWindow
+	if w <= 0 {
+		w = DefaultErrorWindow
+	}
+	n := e.errors.observe(errorFingerprint(rec, context), rec.Time, w)
+	if n < 2 {
+		return
+	}
+	buf.AppendString(e.opts.AttrSeparator)
+	e.withColor(buf, e.opts.Theme.Timestamp(), func() {
+		buf.AppendString("seen ")
+		buf.AppendInt(int64(n))
+		buf.AppendString("x in ")
+		buf.AppendDuration(w)
+		buf.AppendString(" window")
+	})
+}
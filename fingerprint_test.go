@@ -0,0 +1,64 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestErrorCounter(t *testing.T) {
+	c := newErrorCounter()
+	now := time.Now()
+	AssertEqual(t, 1, c.observe("a", now, time.Minute))
+	AssertEqual(t, 2, c.observe("a", now.Add(time.Second), time.Minute))
+	AssertEqual(t, 1, c.observe("b", now.Add(time.Second), time.Minute))
+	AssertEqual(t, 1, c.observe("a", now.Add(time.Minute), time.Minute))
+}
+
+func TestHandler_CountErrors(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, CountErrors: true, ErrorWindow: time.Hour})
+	now := time.Now()
+	for i, err := range []error{errors.New("timeout"), errors.New("timeout"), errors.New("refused"), errors.New("timeout")} {
+		rec := slog.NewRecord(now.Add(time.Duration(i)*time.Second), slog.LevelError, "call failed", 0)
+		rec.Add("err", err)
+		AssertNoError(t, h.Handle(context.Background(), rec))
+	}
+	AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "call failed", 0)))
+	AssertEqual(t, "ERR call failed err=timeout\n"+
+		"ERR call failed err=timeout seen 2x in 1h0m0s window\n"+
+		"ERR call failed err=refused\n"+
+		"ERR call failed err=timeout seen 3x in 1h0m0s window\n"+
+		"INF call failed\n", buf.String())
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelError, "failed", 0)
+	rec.Add(slog.Group("req", "err", errors.New("timeout")))
+	other := slog.NewRecord(time.Time{}, slog.LevelError, "failed", 0)
+	other.Add(slog.Group("req", "err", errors.New("refused")))
+	AssertEqual(t, false, errorFingerprint(rec, nil) == errorFingerprint(other, nil))
+
+	ctx := buffer("component=db")
+	AssertEqual(t, false, errorFingerprint(rec, nil) == errorFingerprint(rec, &ctx))
+}
+
+func TestHandler_CountErrorsWithAttrs(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, CountErrors: true}))
+	logger.With("component", "db").Error("failed")
+	logger.With("component", "api").Error("failed")
+	AssertEqual(t, "ERR failed component=db\nERR failed component=api\n", buf.String())
+}
+
+func TestHandler_CountErrorsPanic(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, CountErrors: true}))
+	logger.Error("failed", "err", panicError{})
+	logger.Error("failed", "err", panicError{})
+	AssertEqual(t, "ERR failed err=!PANIC formatting value: bang\n"+
+		"ERR failed err=!PANIC formatting value: bang seen 2x in 1m0s window\n", buf.String())
+}
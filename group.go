@@ -0,0 +1,148 @@
+package console
+
+import "log/slog"
+
+// groups describes the groups opened with Handler.WithGroup.
+type groups struct {
+	// prefix is the key prefix of attributes in the innermost group,
+	// like "group.subgroup.", or empty at top level.
+	prefix []byte
+	// names are the names of the groups, outermost first.
+	names []string
+	// opened is the number of groups already opened in the handler's
+	// context with nested groups rendering. Groups are only opened once
+	// they hold an attribute.
+	opened int
+	// pad is the padding pending after the last context attribute,
+	// with AlignValues.
+	pad int
+}
+
+// with returns g with the group name nested in it.
+func (g groups) with(name string) groups {
+	return groups{
+		prefix: appendGroupPrefix(append([]byte(nil), g.prefix...), name),
+		names:  append(g.names[:len(g.names):len(g.names)], name),
+		opened: g.opened,
+		pad:    g.pad,
+	}
+}
+
+// writeGroupedAttr writes a within the groups g. With nested or indented
+// groups rendering, the groups not opened yet are opened before a, and
+// g.opened is updated.
+func (e *encoder) writeGroupedAttr(buf *buffer, a slog.Attr, g *groups) {
+	if e.opts.GroupThemes != nil {
+		var ge encoder
+		for _, name := range g.names {
+			e = e.forGroup(name, &ge)
+		}
+	}
+	if !e.opts.NestedGroups && !e.opts.IndentGroups {
+		e.writeAttr(buf, a, g.prefix)
+		return
+	}
+	if g.opened == len(g.names) {
+		e.writeAttrSep(buf, a, nil, true)
+		return
+	}
+	start := buf.Len()
+	for i, name := range g.names[g.opened:] {
+		if e.opts.IndentGroups {
+			e.indentGroup(buf, name, g.opened+i+1)
+		} else {
+			e.openGroup(buf, name, i == 0)
+		}
+	}
+	if !e.writeAttrSep(buf, a, nil, e.opts.IndentGroups) {
+		// Don't output groups without attributes
+		*buf = (*buf)[:start]
+		return
+	}
+	g.opened = len(g.names)
+}
+
+// forGroup returns the encoder to use for the attributes of the group
+// name, which uses the theme associated with it in GroupThemes, if any.
+// That encoder is a copy of e made in scratch.
+func (e *encoder) forGroup(name string, scratch *encoder) *encoder {
+	t, ok := e.opts.GroupThemes[name]
+	if !ok {
+		return e
+	}
+	*scratch = *e
+	scratch.setTheme(t)
+	return scratch
+}
+
+// closeGroups closes the n groups opened with nested groups rendering.
+func (e *encoder) closeGroups(buf *buffer, n int) {
+	if e.opts.IndentGroups {
+		return
+	}
+	if n > 0 {
+		e.dropPad()
+	}
+	for i := 0; i < n; i++ {
+		e.writePunct(buf, '}')
+	}
+}
+
+// openGroup writes the opening of a nested group, preceded by a space if sep is true.
+func (e *encoder) openGroup(buf *buffer, name string, sep bool) {
+	if sep {
+		e.writePad(buf)
+		buf.AppendString(e.opts.AttrSeparator)
+	} else {
+		e.dropPad()
+	}
+	if e.punct == "" {
+		e.withColor(buf, e.opts.Theme.AttrKey(), func() {
+			buf.AppendString(name)
+			buf.AppendString(e.opts.KeyValueSeparator)
+		})
+		buf.AppendByte('{')
+		return
+	}
+	e.writeColoredString(buf, name, e.opts.Theme.AttrKey())
+	e.writeColoredString(buf, e.opts.KeyValueSeparator, e.punct)
+	e.writePunct(buf, '{')
+}
+
+// indentGroup starts a new line for the group name, indented by its depth.
+func (e *encoder) indentGroup(buf *buffer, name string, depth int) {
+	e.dropPad()
+	buf.AppendByte('\n')
+	for i := 0; i < depth; i++ {
+		buf.AppendString("  ")
+	}
+	if e.punct == "" {
+		e.withColor(buf, e.attrGroup, func() {
+			buf.AppendString(name)
+			buf.AppendByte(':')
+		})
+		return
+	}
+	e.writeColoredString(buf, name, e.attrGroup)
+	e.writePunct(buf, ':')
+}
+
+// writeNestedGroup writes the group attribute named key, with its attributes
+// enclosed in braces. It reports whether something was written, as empty
+// groups are ignored.
+func (e *encoder) writeNestedGroup(buf *buffer, key string, attrs []slog.Attr, sep bool) bool {
+	start := buf.Len()
+	e.openGroup(buf, key, sep)
+	written := false
+	for _, a := range attrs {
+		if e.writeAttrSep(buf, a, nil, written) {
+			written = true
+		}
+	}
+	if !written {
+		*buf = (*buf)[:start]
+		return false
+	}
+	e.closeGroups(buf, 1)
+	return true
+}
@@ -5,17 +5,19 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var bufferPool = &sync.Pool{
-	New: func() any { return new(buffer) },
-}
-
 var cwd, _ = os.Getwd()
 
+// DefaultMaxBufferSize is the default value of HandlerOptions.MaxBufferSize.
+const DefaultMaxBufferSize = 64 << 10
+
 // HandlerOptions are options for a ConsoleHandler.
 // A zero HandlerOptions consists entirely of default values.
 type HandlerOptions struct {
@@ -23,6 +25,33 @@ type HandlerOptions struct {
 	// of the log statement and add a SourceKey attribute to the output.
 	AddSource bool
 
+	// SourceAsAttr renders the source code position added by AddSource as
+	// a trailing attribute, like "source=main.go:12", instead of in the
+	// header before the message.
+	SourceAsAttr bool
+
+	// Verbose adds details useful for deep debugging sessions to the header
+	// of every record: the time elapsed since the program started, the id of
+	// the logging goroutine, and the source code position with its absolute
+	// path and function name. It implies AddSource.
+	Verbose bool
+
+	// CIMarkers writes markers around the output of spans, so that it
+	// renders as collapsible sections in the web UI of the selected CI
+	// system. DetectCIMarkers returns the markers for the current one.
+	CIMarkers CIMarkers
+
+	// AddUptime appends a dimmed attribute with key UptimeKey to every
+	// record, holding the time elapsed since UptimeStart, which helps
+	// correlating the output of long running programs without wall clock
+	// times.
+	AddUptime bool
+
+	// UptimeStart is the start of the uptime added by AddUptime, like the
+	// time the handler was created. If zero, the start of the program is
+	// used.
+	UptimeStart time.Time
+
 	// Level reports the minimum record level that will be logged.
 	// The handler discards records with lower levels.
 	// If Level is nil, the handler assumes LevelInfo.
@@ -33,19 +62,411 @@ type HandlerOptions struct {
 	// Disable colorized output
 	NoColor bool
 
-	// TimeFormat is the format used for time.DateTime
+	// ColorProfile converts the colors of the themes to the nearest ones
+	// supported by the profile, like 24-bit colors to the 256 colors
+	// palette. DetectColorProfile returns the profile of the terminal.
+	// The zero value keeps colors as is.
+	ColorProfile ColorProfile
+
+	// ColorFromEnv makes NewHandler decide whether colors are emitted from
+	// the NO_COLOR, FORCE_COLOR, CLICOLOR_FORCE and CLICOLOR environment
+	// variables, and whether the output is a terminal, overriding NoColor.
+	// Leave it unset to ignore the environment. See ColorsFromEnv.
+	ColorFromEnv bool
+
+	// NoValueColor leaves attribute values uncolored, while the header and
+	// keys still are, so that values can be copied without escape codes.
+	NoValueColor bool
+
+	// TimeFormat is the format used for time.DateTime. It may also be one
+	// of TimeFormatUnix, TimeFormatUnixMilli or TimeFormatUnixNano to
+	// render times as Unix epoch timestamps.
 	TimeFormat string
 
+	// FloatFormat is the notation used to render float values. The default,
+	// FloatAuto, switches to the scientific notation for large exponents.
+	FloatFormat FloatFormat
+
+	// HumanizeNumbers renders the integer values of all the attributes in a
+	// short form with a metric suffix, like "1.2k", "3.4M" or "5.6B".
+	HumanizeNumbers bool
+
+	// HumanizeKeys are the keys of the attributes whose integer values are
+	// humanized, like with HumanizeNumbers.
+	HumanizeKeys []string
+
+	// GroupDigitsKeys are the keys of the attributes, typically counts, whose
+	// integer values are rendered with their digits grouped by thousands,
+	// like "1,234,567".
+	GroupDigitsKeys []string
+
+	// DigitSeparator separates the groups of digits of GroupDigitsKeys
+	// values, to follow the conventions of a locale, like "." or " ".
+	// If empty, "," is used.
+	DigitSeparator string
+
+	// Units maps attribute keys to the unit of their values, like "ms" for
+	// "latency", which is appended to the numeric values of these attributes,
+	// like "latency=42ms".
+	Units map[string]string
+
+	// RelativeTimes renders the time values of attributes relative to the
+	// time they are logged, like "3m ago" or "in 2h", rather than formatted
+	// with TimeFormat. The record's timestamp is not affected.
+	RelativeTimes bool
+
 	// Theme defines the colorized output using ANSI escape sequences
 	Theme Theme
+
+	// KeyStyles associates attribute keys with the style of their values,
+	// like Hex("#56b6c2") for "request_id", so that important attributes
+	// stand out. It overrides Theme.AttrValue for these keys.
+	KeyStyles map[string]ANSIMod
+
+	// GroupThemes associates themes with group names. The attributes of
+	// a group having a theme, including its nested groups, are rendered
+	// with that theme rather than Theme, which makes the output of
+	// subsystems logging in their own group stand out.
+	GroupThemes map[string]Theme
+
+	// EncodeTimestamp, if set, renders the record's timestamp instead of
+	// formatting it with TimeFormat. It is only called for non-zero times.
+	// It allows renderings that layouts can't express, like epoch
+	// milliseconds:
+	//
+	//	EncodeTimestamp: func(buf *console.Buffer, t time.Time) {
+	//		buf.AppendInt(t.UnixMilli())
+	//	},
+	//
+	// The output is styled with Theme.Timestamp.
+	EncodeTimestamp func(buf *Buffer, t time.Time)
+
+	// LevelNames associates levels with their label, like "TRC" for a
+	// trace level of -8 or "FTL" for a fatal level of 12, replacing the
+	// label of the nearest standard level below and its delta, like
+	// "DBG-4" or "ERR+4". The label is styled like that standard level.
+	LevelNames map[slog.Leveler]string
+
+	// EnabledFunc, if set, is called for the records below the minimum
+	// level, and enables them if it returns true. It allows forcing verbose
+	// logging for a single request, with a flag carried by its context,
+	// without lowering the level for all of them:
+	//
+	//	EnabledFunc: func(ctx context.Context, l slog.Level) bool {
+	//		return ctx.Value(debugKey{}) != nil
+	//	},
+	EnabledFunc func(ctx context.Context, l slog.Level) bool
+
+	// ExitLevel, if set, terminates the program after writing the records
+	// at or above this level, like console.LevelFatal, once the outputs
+	// are flushed. The program exits with ExitFunc, os.Exit by default,
+	// with status 1. It does so even when the handler is disabled, or when
+	// the level is below the minimum level.
+	ExitLevel slog.Leveler
+
+	// ExitFunc terminates the program for ExitLevel. It defaults to
+	// os.Exit, and can be replaced to run cleanups first, or in tests.
+	ExitFunc func(code int)
+
+	// LevelRules sets the minimum level of the handlers derived with
+	// WithGroup or WithAttrs matching a rule, like the loggers of a
+	// component, in place of Level. A rule is either a group path, like
+	// "db" or "db.pool", matching the handlers in that group or in its
+	// subgroups, or a key and a value separated by '=', like
+	// "component=db", matching the handlers with that attribute, whose key
+	// is prefixed with its groups like "g.component=db" within groups. Group
+	// rules take precedence over attribute rules, and longer group paths
+	// over shorter ones. Levels set with SetLevel don't override rules.
+	LevelRules map[string]slog.Leveler
+
+	// LevelStyles associates levels with the style of their label,
+	// overriding Theme.Level, so that extra levels like LevelTrace or
+	// LevelFatal are told apart from the standard ones.
+	LevelStyles map[slog.Leveler]ANSIMod
+
+	// LevelDelta sets how the levels between the standard ones are
+	// rendered: with a delta suffix, like "INF+2" (the default), as the
+	// standard level below, or as numbers. LevelNames take precedence.
+	LevelDelta LevelDelta
+
+	// LevelWidth pads the level labels with spaces to the given width, so
+	// that messages line up despite deltas like "INF+2" or LevelNames of
+	// various lengths. Longer labels are not truncated.
+	LevelWidth int
+
+	// EncodeLevel, if set, renders the level label instead of the default
+	// 3 letters abbreviation. The output is styled with Theme.Level.
+	EncodeLevel func(buf *Buffer, l slog.Level)
+
+	// EncodeSource, if set, renders the source code position when AddSource
+	// is enabled. frame.File is the absolute path of the file.
+	// The output is styled with Theme.Source.
+	EncodeSource func(buf *Buffer, frame runtime.Frame)
+
+	// EncodeKey, if set, renders attribute keys. group holds the dot
+	// separated names of the enclosing groups, and is empty at top level.
+	// The output is styled with Theme.AttrKey.
+	EncodeKey func(buf *Buffer, group, key string)
+
+	// EncodeFallback, if set, is called for values which have no dedicated
+	// rendering (any kind other than strings, numbers, booleans, times,
+	// durations, errors and fmt.Stringer), before falling back to
+	// slog.Value.String. It returns false to let the default rendering
+	// happen, in which case it must not have written anything.
+	// The output is styled with Theme.AttrValue.
+	EncodeFallback func(buf *Buffer, v slog.Value) bool
+
+	// FormatValue, if set, is called with the key and the resolved value of
+	// every attribute, after redaction, and returns the string to display
+	// instead of the default rendering, like a rounded number or a value
+	// converted to another unit. It returns false to let the default
+	// rendering happen. It is not called for groups.
+	// The output is styled with Theme.AttrValue.
+	FormatValue func(key string, v slog.Value) (string, bool)
+
+	// MaxBufferSize is the capacity above which a buffer used to render
+	// a record is dropped after use rather than returned to the pool, so that
+	// a few huge records do not keep memory pinned forever.
+	// If zero, DefaultMaxBufferSize is used. A negative value disables the limit.
+	MaxBufferSize int
+
+	// InitialBufferSize is the minimum capacity of the buffer used to render
+	// a record, so that handlers logging large records avoid growing it
+	// repeatedly. It should be lower than MaxBufferSize.
+	InitialBufferSize int
+
+	// Disabled discards all the records without formatting them.
+	// Records are also discarded when the output is io.Discard.
+	Disabled bool
+
+	// Metrics, if set, collects counters about the activity of the handler.
+	// A single Metrics may be shared by several handlers.
+	Metrics *Metrics
+
+	// CollectStats enables the statistics returned by Handler.Stats, when
+	// Metrics is not set. They are not collected otherwise, to save their
+	// cost on every record.
+	CollectStats bool
+
+	// MaxValueLength is the maximum length in bytes of a rendered attribute
+	// value, not counting its color codes. Longer values are truncated and
+	// followed by a notice telling how many bytes were dropped. Strings,
+	// errors and fmt.Stringer values are truncated before being rendered.
+	// If zero, values are never truncated.
+	MaxValueLength int
+
+	// Pool selects how the buffers used to render records are reused.
+	Pool PoolMode
+
+	// HideAttrs omits the attributes, printing only the header and the message
+	// of the records. Combined with HideTime and HideLevel, it turns the handler
+	// into plain user-facing output, for instance while another handler
+	// captures the details.
+	HideAttrs bool
+
+	// HideTime omits the timestamp of the records.
+	HideTime bool
+
+	// HideLevel omits the level of the records.
+	HideLevel bool
+
+	// KeyValueSeparator separates attribute keys from their value.
+	// If empty, "=" is used.
+	KeyValueSeparator string
+
+	// AttrSeparator separates attributes from each other and from the
+	// message. If empty, a single space is used.
+	AttrSeparator string
+
+	// BareTrueBools renders the boolean attributes whose value is true with
+	// their key only, like "cached" instead of "cached=true".
+	BareTrueBools bool
+
+	// Redact, if set, masks the values of the attributes whose key designates
+	// a secret, before they are rendered.
+	Redact *Redactor
+
+	// NestedGroups renders the attributes of a group enclosed in braces,
+	// like "group={k=v sub={k=v}}", instead of qualifying their keys with
+	// the group name, like "group.k=v group.sub.k=v".
+	NestedGroups bool
+
+	// HideGroupPrefix renders the attributes of groups with their own key
+	// only, like "k=v" instead of "group.k=v". It has no effect when
+	// NestedGroups is set.
+	HideGroupPrefix bool
+
+	// IndentGroups renders the attributes of each group opened with WithGroup
+	// on their own line, after the group name, indented by the depth of the
+	// group. This produces a tree-like view of hierarchical loggers, with
+	// records spanning several lines. It takes precedence over NestedGroups.
+	IndentGroups bool
+
+	// CorrelationKey, if set, is the key of an attribute, like "request_id",
+	// whose value correlates records. Each record is then preceded by a gutter,
+	// colored after that value, which visually groups consecutive records
+	// sharing the same value. Only top level attributes are considered.
+	CorrelationKey string
+
+	// Indent is the number of indentation steps, of 2 spaces each, inserted
+	// before the message of every record. It's used to nest the records
+	// logged within a Span.
+	Indent int
+
+	// Width is the width of the terminal, in columns, used to draw dividers.
+	// If zero, it's read from the COLUMNS environment variable, and defaults
+	// to DefaultWidth.
+	Width int
+
+	// AdaptiveHeaders pads the timestamp, level and source of each record to
+	// the largest width observed in recent records, so that messages start
+	// on the same column without configuring static widths. The widths are
+	// shared by the handler and the handlers derived from it.
+	AdaptiveHeaders bool
+
+	// AlignValues pads the value of each attribute to the largest width
+	// observed in recent records for the same key, so that the values of
+	// recurring keys, like status=200 and status=404, line up vertically
+	// across consecutive records. The widths are shared by the handler and
+	// the handlers derived from it.
+	AlignValues bool
+
+	// HighlightChanges compares the attributes of each record with the ones
+	// of the previous record having the same keys, like periodic status
+	// lines, and styles the values which changed with
+	// Theme.AttrValueChanged, and the others with Theme.AttrValueUnchanged.
+	// Only the attributes of the records are compared, not the ones added
+	// with WithAttrs.
+	HighlightChanges bool
+
+	// AddDeadline adds a dimmed attribute with key DeadlineKey to the records
+	// handled with a context having a deadline, holding the time remaining
+	// before it, like "deadline=1.2s".
+	AddDeadline bool
+
+	// CountErrors tracks the records at error level or above by fingerprint,
+	// made of their message, the handler's attributes and their error values,
+	// and annotates the repeated ones with their number of occurrences in
+	// the current window, like "seen 14x in 1m0s window", to tell one-off
+	// errors from storms. Windows are consecutive: a window starts with
+	// the first occurrence of an error after the previous one ended.
+	CountErrors bool
+
+	// ErrorWindow is the duration of the windows errors are counted over
+	// with CountErrors. If zero, DefaultErrorWindow is used.
+	ErrorWindow time.Duration
+
+	// Mirrors are secondary destinations the records are also written to,
+	// each with its own minimum level.
+	Mirrors []Mirror
+
+	// Plain disables every decoration at once, producing strictly
+	// "time level message k=v..." lines as a stable format for scripts
+	// parsing the output: colors, source, custom encoders and separators,
+	// group rendering modes, padding, gutters and highlighting are all
+	// turned off, even by SetNoColor, and line breaks in the message and
+	// values are escaped as "\n" and "\r". The other options, like Level,
+	// Mirrors, ExitLevel or CountErrors, are kept. Banner and Divider log
+	// plain records instead of drawing rules.
+	Plain bool
 }
 
 type Handler struct {
-	opts    HandlerOptions
-	out     io.Writer
-	group   string
-	context buffer
-	enc     *encoder
+	opts     HandlerOptions
+	out      *output
+	groups   groups
+	context  buffer
+	ctxAttrs []groupedAttr
+	enc      *encoder
+	level    *levelVar
+	pool     bufferPool
+	mirrors  []mirror
+	stats    *Metrics     // opts.Metrics, or the handler's own, or nil
+	rule     slog.Leveler // Level of the LevelRules matching h, if any
+
+	style *styleVar                 // Style set at runtime
+	built *style                    // Style enc and context were rendered with
+	cache atomic.Pointer[rendering] // Rendering for another style
+}
+
+// output is an io.Writer which can be swapped at runtime.
+// Writes are serialized so that records are never interleaved.
+type output struct {
+	mu      sync.Mutex
+	w       io.Writer
+	discard atomic.Bool // Whether w is io.Discard
+	overlay Overlay     // Drawn after each write, if not nil
+
+	// Value of the correlation key in the last record written
+	lastCorrelation string
+	hasCorrelation  bool
+}
+
+func newOutput(w io.Writer) *output {
+	o := new(output)
+	o.set(w)
+	return o
+}
+
+func (o *output) Write(b []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.write(b)
+}
+
+// write writes b to the underlying writer, clearing and drawing
+// the overlay around it. The caller must hold the lock if needed.
+func (o *output) write(b []byte) (int, error) {
+	if o.overlay == nil {
+		return o.w.Write(b)
+	}
+	o.overlay.Clear(o.w)
+	n, err := o.w.Write(b)
+	o.overlay.Draw(o.w)
+	return n, err
+}
+
+// unlockedOutput writes to an output without locking it.
+type unlockedOutput output
+
+func (o *unlockedOutput) Write(b []byte) (int, error) {
+	return (*output)(o).write(b)
+}
+
+func (o *output) set(w io.Writer) {
+	o.mu.Lock()
+	o.w = w
+	o.discard.Store(w == io.Discard)
+	o.mu.Unlock()
+}
+
+// levelVar holds a slog.Leveler which can be atomically swapped.
+// It is shared by a handler and all the handlers derived from it
+// with WithAttrs and WithGroup.
+type levelVar struct {
+	l atomic.Pointer[slog.Leveler]
+}
+
+func newLevelVar(l slog.Leveler) *levelVar {
+	v := new(levelVar)
+	v.set(l)
+	return v
+}
+
+func (v *levelVar) set(l slog.Leveler) {
+	v.l.Store(&l)
+}
+
+func (v *levelVar) get() slog.Leveler {
+	return *v.l.Load()
+}
+
+// groupedAttr is an attribute added with WithAttrs, along with
+// the groups it was added in. They are retained so that the context
+// can be rendered again when the options change.
+type groupedAttr struct {
+	groups groups
+	attr   slog.Attr
 }
 
 var _ slog.Handler = (*Handler)(nil)
@@ -57,81 +478,279 @@ func NewHandler(out io.Writer, opts *HandlerOptions) *Handler {
 	if opts == nil {
 		opts = new(HandlerOptions)
 	}
-	if opts.Level == nil {
-		opts.Level = slog.LevelInfo
+	o := *opts // Copy struct
+	o.setDefaults()
+	if o.ColorFromEnv {
+		o.NoColor = !ColorsFromEnv(out)
+	}
+	return &Handler{
+		opts:    o,
+		out:     newOutput(out),
+		groups:  groups{},
+		context: nil,
+		enc:     newEncoder(o, nil),
+		level:   newLevelVar(o.Level),
+		pool:    newBufferPool(o.Pool),
+		mirrors: newMirrors(o.Mirrors, nil),
+		stats:   newStats(o, nil),
+		style:   new(styleVar),
+	}
+}
+
+// Render formats rec the same way a Handler created with opts would,
+// and returns the resulting line, including the trailing newline.
+// If opts is nil, the default options are used.
+func Render(rec slog.Record, opts *HandlerOptions) []byte {
+	if opts == nil {
+		opts = new(HandlerOptions)
+	}
+	o := *opts
+	o.setDefaults()
+	var buf buffer
+	newEncoder(o, nil).writeRecord(&buf, rec, nil, groups{})
+	return buf.Bytes()
+}
+
+func (o *HandlerOptions) setDefaults() {
+	o.applyPlain()
+	if o.Verbose {
+		o.AddSource = true
+	}
+	if o.Level == nil {
+		o.Level = slog.LevelInfo
+	}
+	if o.ExitFunc == nil {
+		o.ExitFunc = os.Exit
+	}
+	if o.TimeFormat == "" {
+		o.TimeFormat = time.DateTime
+	}
+	if o.Theme == nil {
+		o.Theme = NewDefaultTheme()
+	}
+	o.applyProfile()
+	if o.KeyValueSeparator == "" {
+		o.KeyValueSeparator = "="
+	}
+	if o.AttrSeparator == "" {
+		o.AttrSeparator = " "
 	}
-	if opts.TimeFormat == "" {
-		opts.TimeFormat = time.DateTime
+	if o.MaxBufferSize == 0 {
+		o.MaxBufferSize = DefaultMaxBufferSize
 	}
-	if opts.Theme == nil {
-		opts.Theme = NewDefaultTheme()
+}
+
+// byLevel returns m indexed by level, or nil if empty.
+func byLevel[V any](m map[slog.Leveler]V) map[slog.Level]V {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[slog.Level]V, len(m))
+	for l, v := range m {
+		res[l.Level()] = v
 	}
+	return res
+}
+
+// Options returns a copy of the options in effect for h, with
+// defaults resolved. Level reports the leveler currently in use.
+func (h *Handler) Options() HandlerOptions {
+	opts := h.opts
+	if s := h.style.p.Load(); s != h.built {
+		s.apply(&opts)
+	}
+	opts.Level = h.level.get()
+	return opts
+}
+
+// WithOptions returns a copy of the handler whose options have been
+// modified by fn. Attributes and groups accumulated with WithAttrs
+// and WithGroup are preserved, and rendered again using the new options.
+func (h *Handler) WithOptions(fn func(*HandlerOptions)) *Handler {
+	opts := h.Options()
+	fn(&opts)
+	opts.setDefaults()
+	enc := newEncoder(opts, h.enc)
+	newCtx, g := h.renderContext(enc)
 	return &Handler{
-		opts:    *opts, // Copy struct
-		out:     out,
-		group:   "",
-		context: nil,
-		enc:     &encoder{opts: *opts},
+		opts:     opts,
+		out:      h.out,
+		groups:   g,
+		context:  newCtx,
+		ctxAttrs: h.ctxAttrs,
+		rule:     opts.levelRule(g, h.ctxAttrs),
+		enc:      enc,
+		level:    newLevelVar(opts.Level),
+		pool:     newBufferPool(opts.Pool),
+		mirrors:  newMirrors(opts.Mirrors, h.mirrors),
+		stats:    newStats(opts, h.stats),
+		style:    new(styleVar),
 	}
 }
 
 // Enabled implements slog.Handler.
-func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
-	return l >= h.opts.Level.Level()
+func (h *Handler) Enabled(ctx context.Context, l slog.Level) bool {
+	if h.exits(l) {
+		return true
+	}
+	if h.disabled() {
+		return false
+	}
+	return (h.levelEnabled(ctx, l) && !h.out.discard.Load()) || h.mirrorEnabled(l)
+}
+
+// exits reports whether records at level l terminate the program.
+func (h *Handler) exits(l slog.Level) bool {
+	return h.opts.ExitLevel != nil && l >= h.opts.ExitLevel.Level()
+}
+
+func (h *Handler) disabled() bool {
+	return h.opts.Disabled || (h.out.discard.Load() && h.mirrors == nil)
+}
+
+// SetOutput redirects the output of h, and of every handler sharing
+// its output, to w. It is safe to call concurrently with Handle.
+func (h *Handler) SetOutput(w io.Writer) {
+	h.out.set(w)
+}
+
+// Level returns the minimum level currently enabled by the handler.
+func (h *Handler) Level() slog.Level {
+	return h.minLevel()
+}
+
+// SetLevel changes the minimum record level that will be logged.
+// The change applies to h and to every handler derived from it
+// with WithAttrs and WithGroup. It is safe for concurrent use.
+// If l is nil, slog.LevelInfo is used.
+func (h *Handler) SetLevel(l slog.Leveler) {
+	if l == nil {
+		l = slog.LevelInfo
+	}
+	h.level.set(l)
 }
 
 // Handle implements slog.Handler.
-func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
-	buf := bufferPool.Get().(*buffer)
-
-	h.enc.writeTimestamp(buf, rec.Time)
-	h.enc.writeLevel(buf, rec.Level)
-	if h.opts.AddSource && rec.PC > 0 {
-		h.enc.writeSource(buf, rec.PC, cwd)
-	}
-	h.enc.writeMessage(buf, rec.Level, rec.Message)
-	buf.copy(&h.context)
-	rec.Attrs(func(a slog.Attr) bool {
-		h.enc.writeAttr(buf, a, h.group)
-		return true
-	})
-	h.enc.NewLine(buf)
-	if _, err := buf.WriteTo(h.out); err != nil {
-		buf.Reset()
-		bufferPool.Put(buf)
-		return err
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	m := h.stats
+	m.recordHandled(rec.Level)
+	if h.disabled() {
+		m.recordDropped()
+		if h.exits(rec.Level) {
+			h.exit(ctx, 1)
+		}
+		return nil
+	}
+	if h.opts.AddDeadline {
+		rec = withDeadline(ctx, rec)
+	}
+	buf := h.pool.get()
+	buf.Grow(h.opts.InitialBufferSize)
+
+	enc, context, _ := h.rendering()
+	enc.writeRecord(buf, rec, context, h.groups)
+	var n int64
+	var err error
+	var mirrorErr error
+	toOut := true
+	if h.mirrors != nil {
+		mirrorErr = h.writeMirrors(*buf, rec.Level)
+	}
+	if h.mirrors != nil || h.opts.ExitLevel != nil {
+		// Enabled may have let the record through for a mirror, or to exit
+		toOut = h.levelEnabled(ctx, rec.Level) && !h.out.discard.Load()
+	}
+	written := toOut
+	switch rb := recordBufferFrom(ctx); {
+	case !toOut:
+	case rb != nil:
+		written = false // Counted once flushed
+		err = rb.add(h.out, *buf, rec.Level, h.opts.Plain, m)
+	case h.opts.CorrelationKey != "":
+		val, found := h.correlationValue(rec)
+		n, err = h.out.writeCorrelated(buf, val, found, !enc.opts.NoColor)
+	case h.opts.Pool == PoolSingleGoroutine:
+		n, err = buf.WriteTo((*unlockedOutput)(h.out))
+	default:
+		n, err = buf.WriteTo(h.out)
+	}
+	if written {
+		m.recordWritten(n, err)
+	}
+	if mirrorErr != nil {
+		m.recordWriteError(mirrorErr)
+		if err == nil {
+			err = mirrorErr
+		}
+	}
+	h.releaseBuffer(buf)
+	if h.exits(rec.Level) {
+		h.exit(ctx, 1)
+	}
+	return err
+}
+
+// releaseBuffer returns buf to the pool, unless it grew too large.
+func (h *Handler) releaseBuffer(buf *buffer) {
+	if h.opts.MaxBufferSize > 0 && buf.Cap() > h.opts.MaxBufferSize {
+		return
 	}
-	bufferPool.Put(buf)
-	return nil
+	buf.Reset()
+	h.pool.put(buf)
 }
 
 // WithAttrs implements slog.Handler.
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	newCtx := h.context
+	enc, context, s := h.rendering()
+	newCtx := *context
+	ctxAttrs := slices.Clip(h.ctxAttrs)
+	g := h.groups
+	var pe encoder
+	enc = enc.withPad(&g.pad, &pe)
 	for _, a := range attrs {
-		h.enc.writeAttr(&newCtx, a, h.group)
+		ctxAttrs = append(ctxAttrs, groupedAttr{groups: g, attr: a})
+		enc.writeGroupedAttr(&newCtx, a, &g)
 	}
 	newCtx.Clip()
 	return &Handler{
-		opts:    h.opts,
-		out:     h.out,
-		group:   h.group,
-		context: newCtx,
-		enc:     h.enc,
+		opts:     h.opts,
+		out:      h.out,
+		groups:   g,
+		context:  newCtx,
+		ctxAttrs: ctxAttrs,
+		rule:     h.opts.levelRule(g, ctxAttrs),
+		enc:      enc,
+		level:    h.level,
+		pool:     h.pool,
+		mirrors:  h.mirrors,
+		stats:    h.stats,
+		style:    h.style,
+		built:    s,
 	}
 }
 
 // WithGroup implements slog.Handler.
 func (h *Handler) WithGroup(name string) slog.Handler {
 	name = strings.TrimSpace(name)
-	if h.group != "" {
-		name = h.group + "." + name
+	// '- If the name is empty, WithGroup returns the receiver.'
+	// https://pkg.go.dev/log/slog@master#Handler
+	if name == "" {
+		return h
 	}
+	g := h.groups.with(name)
 	return &Handler{
-		opts:    h.opts,
-		out:     h.out,
-		group:   name,
-		context: h.context,
-		enc:     h.enc,
+		opts:     h.opts,
+		out:      h.out,
+		groups:   g,
+		context:  h.context,
+		ctxAttrs: h.ctxAttrs,
+		rule:     h.opts.levelRule(g, h.ctxAttrs),
+		enc:      h.enc,
+		level:    h.level,
+		pool:     h.pool,
+		mirrors:  h.mirrors,
+		stats:    h.stats,
+		style:    h.style,
+		built:    h.built,
 	}
 }
@@ -5,10 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -135,10 +138,11 @@ func TestHandler_GroupInline(t *testing.T) {
 	rec.AddAttrs(
 		slog.Group("group", slog.String("foo", "bar")),
 		slog.Group("", slog.String("foo", "bar")),
+		slog.Group("outer", slog.Group("", slog.String("foo", "bar"))),
 	)
 	AssertNoError(t, h.Handle(context.Background(), rec))
 
-	expected := fmt.Sprintf("%s INF foobar group.foo=bar foo=bar\n", now.Format(time.DateTime))
+	expected := fmt.Sprintf("%s INF foobar group.foo=bar foo=bar outer.foo=bar\n", now.Format(time.DateTime))
 	AssertEqual(t, expected, buf.String())
 }
 
@@ -219,15 +223,17 @@ func TestHandler_WithGroup(t *testing.T) {
 
 func TestHandler_Levels(t *testing.T) {
 	levels := map[slog.Level]string{
-		slog.LevelDebug - 1: "DBG-1",
-		slog.LevelDebug:     "DBG",
-		slog.LevelDebug + 1: "DBG+1",
-		slog.LevelInfo:      "INF",
-		slog.LevelInfo + 1:  "INF+1",
-		slog.LevelWarn:      "WRN",
-		slog.LevelWarn + 1:  "WRN+1",
-		slog.LevelError:     "ERR",
-		slog.LevelError + 1: "ERR+1",
+		slog.LevelDebug - 8:  "DBG-8",
+		slog.LevelDebug - 1:  "DBG-1",
+		slog.LevelDebug:      "DBG",
+		slog.LevelDebug + 1:  "DBG+1",
+		slog.LevelInfo:       "INF",
+		slog.LevelInfo + 1:   "INF+1",
+		slog.LevelWarn:       "WRN",
+		slog.LevelWarn + 1:   "WRN+1",
+		slog.LevelError:      "ERR",
+		slog.LevelError + 1:  "ERR+1",
+		slog.LevelError + 12: "ERR+12",
 	}
 
 	for l := range levels {
@@ -248,6 +254,119 @@ func TestHandler_Levels(t *testing.T) {
 	}
 }
 
+func TestHandler_LevelNames(t *testing.T) {
+	const levelTrace, levelFatal = slog.Level(-8), slog.Level(12)
+	opts := &HandlerOptions{
+		Level:      levelTrace,
+		HideTime:   true,
+		LevelNames: map[slog.Leveler]string{levelTrace: "TRC", levelFatal: "FTL"},
+	}
+	theme := NewDefaultTheme()
+	for l, expected := range map[slog.Level]string{
+		levelTrace:          styled("TRC", theme.LevelDebug()),
+		levelTrace + 1:      styled("DBG-3", theme.LevelDebug()),
+		slog.LevelInfo:      styled("INF", theme.LevelInfo()),
+		levelFatal:          styled("FTL", theme.LevelError()),
+		slog.LevelError + 1: styled("ERR+1", theme.LevelError()),
+	} {
+		rec := slog.NewRecord(time.Time{}, l, "msg", 0)
+		out := string(Render(rec, opts))
+		msgStyle := theme.Message()
+		if l < slog.LevelInfo {
+			msgStyle = theme.MessageDebug()
+		}
+		AssertEqual(t, expected+" "+styled("msg", msgStyle)+"\n", out)
+
+		m, err := ParseLine(out, opts)
+		AssertNoError(t, err)
+		AssertEqual(t, any(l), m[slog.LevelKey])
+	}
+}
+
+func TestHandler_LevelStyles(t *testing.T) {
+	theme := NewDefaultTheme()
+	fatal := ToANSICode(Bold, Magenta)
+	opts := &HandlerOptions{
+		Level:       LevelTrace,
+		HideTime:    true,
+		Theme:       theme,
+		LevelNames:  map[slog.Leveler]string{LevelTrace: "TRC", LevelNotice: "NTC", LevelFatal: "FTL"},
+		LevelStyles: map[slog.Leveler]ANSIMod{LevelTrace: ToANSICode(Faint), LevelFatal: fatal},
+	}
+	for l, expected := range map[slog.Level]string{
+		LevelTrace:      styled("TRC", ToANSICode(Faint)),
+		slog.LevelDebug: styled("DBG", theme.LevelDebug()),
+		LevelNotice:     styled("NTC", theme.LevelInfo()),
+		LevelFatal:      styled("FTL", fatal),
+	} {
+		out := string(Render(slog.NewRecord(time.Time{}, l, "msg", 0), opts))
+		AssertEqual(t, true, strings.HasPrefix(out, expected+" "))
+	}
+
+	// Custom themes choose the style of levels
+	custom := levelTheme{theme}
+	out := string(Render(slog.NewRecord(time.Time{}, LevelTrace, "msg", 0), &HandlerOptions{Level: LevelTrace, Theme: custom}))
+	AssertEqual(t, true, strings.HasPrefix(out, styled("DBG-4", ToANSICode(Italic))+" "))
+}
+
+type levelTheme struct{ Theme }
+
+func (t levelTheme) Level(l slog.Level) ANSIMod {
+	if l < slog.LevelDebug {
+		return ToANSICode(Italic)
+	}
+	return t.Theme.Level(l)
+}
+
+func TestHandler_LevelWidth(t *testing.T) {
+	opts := &HandlerOptions{NoColor: true, HideTime: true, Level: slog.LevelDebug - 4, LevelWidth: 5, LevelNames: map[slog.Leveler]string{slog.LevelWarn: "WARN"}}
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, opts)
+	for _, l := range []slog.Level{slog.LevelInfo, slog.LevelWarn, slog.LevelError + 2, slog.LevelDebug - 4} {
+		AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, l, "msg", 0)))
+	}
+	AssertEqual(t, "INF   msg\nWARN  msg\nERR+2 msg\nDBG-4 msg\n", buf.String())
+	m, err := ParseLine("INF   msg k=v\n", opts)
+	AssertNoError(t, err)
+	AssertEqual(t, any("msg"), m[slog.MessageKey])
+
+	theme := NewDefaultTheme()
+	opts = &HandlerOptions{HideTime: true, LevelWidth: 4, Theme: theme, EncodeLevel: func(buf *Buffer, l slog.Level) { buf.WriteString("I") }}
+	AssertEqual(t, styled("I", theme.LevelInfo())+"    "+styled("msg", theme.Message())+"\n", string(Render(slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0), opts)))
+}
+
+func TestHandler_LevelDelta(t *testing.T) {
+	levels := []slog.Level{slog.LevelInfo, slog.LevelWarn + 2, slog.LevelDebug - 4}
+	for _, tc := range []struct {
+		delta    LevelDelta
+		expected string
+	}{
+		{LevelDeltaShow, "INF msg\nWRN+2 msg\nDBG-4 msg\nNAMED msg\n"},
+		{LevelDeltaHide, "INF msg\nWRN msg\nDBG msg\nNAMED msg\n"},
+		{LevelDeltaNumeric, "0 msg\n6 msg\n-8 msg\nNAMED msg\n"},
+	} {
+		opts := &HandlerOptions{NoColor: true, HideTime: true, Level: slog.LevelDebug - 4, LevelDelta: tc.delta, LevelNames: map[slog.Leveler]string{slog.LevelError: "NAMED"}}
+		buf := bytes.Buffer{}
+		h := NewHandler(&buf, opts)
+		for _, l := range append(levels, slog.LevelError) {
+			AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, l, "msg", 0)))
+		}
+		AssertEqual(t, tc.expected, buf.String())
+	}
+
+	opts := &HandlerOptions{NoColor: true, LevelDelta: LevelDeltaNumeric}
+	m, err := ParseLine(string(Render(slog.NewRecord(time.Now(), slog.LevelDebug, "msg", 0), opts)), opts)
+	AssertNoError(t, err)
+	AssertEqual(t, any(slog.LevelDebug), m[slog.LevelKey])
+}
+
+func styled(s string, m ANSIMod) string {
+	if m == "" {
+		return s
+	}
+	return string(m) + s + string(ResetMod)
+}
+
 func TestHandler_Source(t *testing.T) {
 	buf := bytes.Buffer{}
 	h := NewHandler(&buf, &HandlerOptions{NoColor: true, AddSource: true})
@@ -282,6 +401,15 @@ func TestThemes(t *testing.T) {
 	for _, theme := range []Theme{
 		NewDefaultTheme(),
 		NewBrightTheme(),
+		NewTrueColorTheme(),
+		New256Theme(),
+		NewLightTheme(),
+		NewDraculaTheme(),
+		NewSolarizedDarkTheme(),
+		NewNordTheme(),
+		NewMonokaiTheme(),
+		NewColorblindTheme(),
+		NewColorblindBasicTheme(),
 	} {
 		t.Run(theme.Name(), func(t *testing.T) {
 			level := slog.LevelInfo
@@ -332,7 +460,7 @@ func TestThemes(t *testing.T) {
 					// Source
 					if theme.Source() != "" {
 						checkANSIMod(t, "Source", theme.Source())
-						checkANSIMod(t, "AttrKey", theme.AttrKey())
+						checkANSIMod(t, "SourceSeparator", sourceSeparatorStyle(theme))
 					}
 
 					// Message
@@ -352,6 +480,11 @@ func TestThemes(t *testing.T) {
 							checkANSIMod(t, "AttrKey", theme.AttrKey())
 						}
 
+						// Punctuation
+						if punctuationStyle(theme) != "" {
+							checkANSIMod(t, "Punctuation", punctuationStyle(theme))
+						}
+
 						// AttrValue
 						if theme.AttrValue() != "" {
 							checkANSIMod(t, "AttrValue", theme.AttrValue())
@@ -434,3 +567,580 @@ func TestThemes(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_WithOptions(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true})
+	h2 := h.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("group").(*Handler)
+	h3 := h2.WithOptions(func(o *HandlerOptions) {
+		o.Level = slog.LevelDebug
+		o.TimeFormat = time.Kitchen
+	})
+	AssertEqual(t, false, h2.Enabled(context.Background(), slog.LevelDebug))
+	AssertEqual(t, true, h3.Enabled(context.Background(), slog.LevelDebug))
+
+	now := time.Now()
+	rec := slog.NewRecord(now, slog.LevelDebug, "foobar", 0)
+	rec.Add("int", 12)
+	AssertNoError(t, h3.Handle(context.Background(), rec))
+	AssertEqual(t, fmt.Sprintf("%s DBG foobar foo=bar group.int=12\n", now.Format(time.Kitchen)), buf.String())
+
+	buf.Reset()
+	h4 := h3.WithOptions(func(o *HandlerOptions) { o.NoColor = false })
+	AssertNoError(t, h4.Handle(context.Background(), rec))
+	AssertEqual(t, true, bytes.Contains(buf.Bytes(), []byte(string(h4.opts.Theme.AttrKey())+"foo="+string(ResetMod))))
+}
+
+func TestHandler_SetLevel(t *testing.T) {
+	h := NewHandler(nopWriter, nil)
+	h2 := h.WithGroup("group").WithAttrs([]slog.Attr{slog.Int("int", 12)})
+	AssertEqual(t, slog.LevelInfo, h.Level())
+	AssertEqual(t, false, h2.Enabled(context.Background(), slog.LevelDebug))
+
+	h.SetLevel(slog.LevelDebug)
+	AssertEqual(t, slog.LevelDebug, h.Level())
+	AssertEqual(t, true, h.Enabled(context.Background(), slog.LevelDebug))
+	AssertEqual(t, true, h2.Enabled(context.Background(), slog.LevelDebug))
+
+	lvl := new(slog.LevelVar)
+	lvl.Set(slog.LevelError)
+	h2.(*Handler).SetLevel(lvl)
+	AssertEqual(t, false, h.Enabled(context.Background(), slog.LevelWarn))
+	lvl.Set(slog.LevelWarn)
+	AssertEqual(t, true, h.Enabled(context.Background(), slog.LevelWarn))
+
+	h.SetLevel(nil)
+	AssertEqual(t, slog.LevelInfo, h.Level())
+}
+
+func TestHandler_SetOutput(t *testing.T) {
+	buf1, buf2 := bytes.Buffer{}, bytes.Buffer{}
+	h := NewHandler(&buf1, &HandlerOptions{NoColor: true})
+	h2 := h.WithAttrs([]slog.Attr{slog.String("foo", "bar")})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	AssertNoError(t, h2.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar foo=bar\n", buf1.String())
+
+	h.SetOutput(&buf2)
+	AssertNoError(t, h2.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar foo=bar\n", buf1.String())
+	AssertEqual(t, "INF foobar foo=bar\n", buf2.String())
+}
+
+func TestHandler_Options(t *testing.T) {
+	h := NewHandler(io.Discard, nil)
+	opts := h.Options()
+	AssertEqual(t, slog.Leveler(slog.LevelInfo), opts.Level)
+	AssertEqual(t, time.DateTime, opts.TimeFormat)
+	AssertEqual(t, "Default", opts.Theme.Name())
+
+	h.SetLevel(slog.LevelWarn)
+	AssertEqual(t, slog.Leveler(slog.LevelWarn), h.Options().Level)
+
+	opts.TimeFormat = time.Kitchen
+	AssertEqual(t, time.DateTime, h.Options().TimeFormat)
+}
+
+func TestRender(t *testing.T) {
+	now := time.Now()
+	rec := slog.NewRecord(now, slog.LevelWarn, "foobar", 0)
+	rec.Add("foo", "bar")
+	AssertEqual(t, fmt.Sprintf("%s WRN foobar foo=bar\n", now.Format(time.DateTime)), string(Render(rec, &HandlerOptions{NoColor: true})))
+
+	buf := bytes.Buffer{}
+	AssertNoError(t, NewHandler(&buf, nil).Handle(context.Background(), rec))
+	AssertEqual(t, buf.String(), string(Render(rec, nil)))
+}
+
+func TestEncoder(t *testing.T) {
+	enc := NewEncoder(&HandlerOptions{NoColor: true})
+	buf := Buffer{}
+	now := time.Now()
+	enc.WriteTimestamp(&buf, now)
+	enc.WriteLevel(&buf, slog.LevelError)
+	enc.WriteMessage(&buf, slog.LevelError, "foobar")
+	enc.WriteAttr(&buf, slog.Int("int", 12), "group")
+	_ = buf.WriteByte(' ')
+	enc.WriteValue(&buf, slog.AnyValue(&theValuer{"ok"}))
+	enc.NewLine(&buf)
+	AssertEqual(t, fmt.Sprintf("%s ERR foobar group.int=12 The word is 'ok'\n", now.Format(time.DateTime)), buf.String())
+
+	buf.Reset()
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	enc.WriteRecord(&buf, rec)
+	AssertEqual(t, string(Render(rec, &HandlerOptions{NoColor: true})), buf.String())
+}
+
+func TestHandler_FieldEncoders(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{
+		NoColor:   true,
+		AddSource: true,
+		EncodeTimestamp: func(buf *Buffer, t time.Time) {
+			_, _ = buf.WriteString(strconv.FormatInt(t.Unix(), 10))
+		},
+		EncodeLevel: func(buf *Buffer, l slog.Level) {
+			_, _ = buf.WriteString(l.String())
+		},
+		EncodeSource: func(buf *Buffer, frame runtime.Frame) {
+			_, _ = buf.WriteString(filepath.Base(frame.File))
+		},
+		EncodeKey: func(buf *Buffer, group, key string) {
+			if group != "" {
+				_, _ = buf.WriteString(group)
+				_ = buf.WriteByte('/')
+			}
+			_, _ = buf.WriteString(key)
+		},
+	})
+	pc, _, _, _ := runtime.Caller(0)
+	now := time.Now()
+	rec := slog.NewRecord(now, slog.LevelWarn, "foobar", pc)
+	rec.Add("foo", "bar")
+	AssertNoError(t, h.WithGroup("group").Handle(context.Background(), rec))
+	AssertEqual(t, fmt.Sprintf("%d WARN handler_test.go > foobar group/foo=bar\n", now.Unix()), buf.String())
+}
+
+func TestHandler_EncodeFallback(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{
+		EncodeFallback: func(buf *Buffer, v slog.Value) bool {
+			if ns, ok := v.Any().(noStringer); ok {
+				_, _ = buf.WriteString("foo:" + ns.Foo)
+				return true
+			}
+			return false
+		},
+		NoColor: true,
+	})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("ns", noStringer{Foo: "bar"}, "other", []int{1, 2}, "str", theStringer{})
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar ns=foo:bar other=[1 2] str=stringer\n", buf.String())
+}
+
+func TestHandler_MaxBufferSize(t *testing.T) {
+	h := NewHandler(io.Discard, &HandlerOptions{MaxBufferSize: 128})
+	AssertEqual(t, 128, h.Options().MaxBufferSize)
+	AssertEqual(t, DefaultMaxBufferSize, NewHandler(io.Discard, nil).Options().MaxBufferSize)
+
+	buf := new(buffer)
+	buf.Grow(256)
+	h.releaseBuffer(buf)
+	for i := 0; i < 10; i++ {
+		AssertNotEqual(t, buf, h.pool.get())
+	}
+
+	h = NewHandler(nopWriter, &HandlerOptions{MaxBufferSize: -1})
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, strings.Repeat("a", 1024), 0)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+}
+
+func TestHandler_InitialBufferSize(t *testing.T) {
+	var capacity int
+	w := writerFunc(func(b []byte) (int, error) {
+		capacity = cap(b)
+		return len(b), nil
+	})
+	h := NewHandler(w, &HandlerOptions{InitialBufferSize: 4096})
+	AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "foobar", 0)))
+	AssertGreaterOrEqual(t, 4096, capacity)
+}
+
+func TestHandler_Disabled(t *testing.T) {
+	buf := bytes.Buffer{}
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, Disabled: true})
+	AssertEqual(t, false, h.Enabled(context.Background(), slog.LevelError))
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertZero(t, buf.Len())
+
+	h = h.WithOptions(func(o *HandlerOptions) { o.Disabled = false })
+	AssertEqual(t, true, h.Enabled(context.Background(), slog.LevelError))
+	h.SetOutput(io.Discard)
+	AssertEqual(t, false, h.Enabled(context.Background(), slog.LevelError))
+	h.SetOutput(&buf)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar\n", buf.String())
+}
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+type panicError struct{}
+
+func (panicError) Error() string { panic("bang") }
+
+func TestHandler_PanicRecovery(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, nil)
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("str", panicStringer{}, "err", panicError{}, "foo", "bar")
+	AssertNoError(t, h.WithOptions(func(o *HandlerOptions) { o.NoColor = true }).Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar str=!PANIC formatting value: boom err=!PANIC formatting value: bang foo=bar\n", buf.String())
+
+	buf.Reset()
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	theme := h.Options().Theme
+	AssertEqual(t, true, strings.Contains(buf.String(), "str="+string(ResetMod)+string(theme.AttrValueError())+"!PANIC formatting value: boom"+string(ResetMod)))
+}
+
+func TestHandler_MaxValueLength(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, MaxValueLength: 5})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("short", "abc", "exact", "abcde", "long", "abcdefghij", "utf8", "abcd€", "int", 1234567)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar short=abc exact=abcde long=abcde...[truncated 5 bytes] utf8=abcd...[truncated 3 bytes] int=12345...[truncated 2 bytes]\n", buf.String())
+
+	buf.Reset()
+	h = NewHandler(&buf, &HandlerOptions{MaxValueLength: 5, Theme: NewBrightTheme()})
+	rec = slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("err", errors.New("the error"))
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, true, strings.HasSuffix(buf.String(), string(NewBrightTheme().AttrValueError())+"the e"+string(ResetMod)+"...[truncated 4 bytes]\n"))
+}
+
+type largeStringer string
+
+func (s largeStringer) String() string { return string(s) }
+
+func TestHandler_MaxValueLengthBuffer(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, MaxValueLength: 5, Pool: PoolSingleGoroutine})
+	large := strings.Repeat("a", 1<<20)
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("str", large, "err", errors.New(large), "stringer", largeStringer(large))
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar str=aaaaa...[truncated 1048571 bytes] err=aaaaa...[truncated 1048571 bytes] stringer=aaaaa...[truncated 1048571 bytes]\n", buf.String())
+	b := h.pool.get()
+	AssertEqual(t, true, b.Cap() < 1<<20)
+	h.pool.put(b)
+}
+
+func TestHandler_PoolSingleGoroutine(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, Pool: PoolSingleGoroutine})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	b := h.pool.get()
+	AssertZero(t, b.Len())
+	AssertNotEqual(t, 0, b.Cap())
+	h.pool.put(b)
+	AssertNoError(t, h.WithGroup("group").Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar\nINF foobar\n", buf.String())
+}
+
+func TestHandler_Pool(t *testing.T) {
+	h := NewHandler(nopWriter, nil)
+	AssertEqual(t, sharedPool, h.pool)
+	AssertEqual(t, bufferPool(noPool{}), NewHandler(nopWriter, &HandlerOptions{Pool: PoolNone}).pool)
+
+	h = NewHandler(nopWriter, &HandlerOptions{Pool: PoolLocal})
+	AssertEqual(t, h.pool, h.WithGroup("group").(*Handler).pool)
+	for _, mode := range []PoolMode{PoolShared, PoolLocal, PoolNone, PoolSingleGoroutine} {
+		h := NewHandler(nopWriter, &HandlerOptions{Pool: mode})
+		AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "foobar", 0)))
+	}
+}
+
+func TestHandler_NestedGroups(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, NestedGroups: true})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("int", 12, slog.Group("group", "foo", "bar", slog.Group("sub", "a", 1), slog.Group("empty")), slog.Group("", "inline", true))
+
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar int=12 group={foo=bar sub={a=1}} inline=true\n", buf.String())
+
+	buf.Reset()
+	h2 := h.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("g1").WithGroup("g2")
+	AssertNoError(t, h2.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar foo=bar g1={g2={int=12 group={foo=bar sub={a=1}} inline=true}}\n", buf.String())
+
+	buf.Reset()
+	AssertNoError(t, h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)))
+	AssertEqual(t, "INF foobar foo=bar\n", buf.String())
+
+	buf.Reset()
+	h3 := h2.WithAttrs([]slog.Attr{slog.String("a", "b")}).WithGroup("g3").WithAttrs([]slog.Attr{slog.String("c", "d")})
+	AssertNoError(t, h3.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)))
+	AssertEqual(t, "INF foobar foo=bar g1={g2={a=b g3={c=d}}}\n", buf.String())
+
+	buf.Reset()
+	rec = slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("e", "f")
+	AssertNoError(t, h3.(*Handler).WithOptions(func(o *HandlerOptions) { o.TimeFormat = time.Kitchen }).Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar foo=bar g1={g2={a=b g3={c=d e=f}}}\n", buf.String())
+}
+
+func TestHandler_HideGroupPrefix(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideGroupPrefix: true})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("int", 12, slog.Group("group", "foo", "bar", slog.Group("sub", "a", 1)))
+	AssertNoError(t, h.WithAttrs([]slog.Attr{slog.String("b", "c")}).WithGroup("g1").WithAttrs([]slog.Attr{slog.String("d", "e")}).Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar b=c d=e int=12 foo=bar a=1\n", buf.String())
+}
+
+func TestHandler_AttrGroupStyle(t *testing.T) {
+	buf := bytes.Buffer{}
+	theme := NewBrightTheme()
+	h := NewHandler(&buf, &HandlerOptions{Theme: theme})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("k", "v")
+	AssertNoError(t, h.WithGroup("group").WithGroup("sub").Handle(context.Background(), rec))
+	group := func(s string) string { return string(attrGroupStyle(theme)) + s + string(ResetMod) }
+	punct := func(s string) string { return string(punctuationStyle(theme)) + s + string(ResetMod) }
+	expected := group("group") + punct(".") + group("sub") + punct(".") + string(theme.AttrKey()) + "k" + string(ResetMod) + punct("=") + "v\n"
+	AssertEqual(t, true, strings.HasSuffix(buf.String(), expected))
+}
+
+func TestHandler_WithGroupEmpty(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true})
+	AssertEqual(t, slog.Handler(h), h.WithGroup(""))
+	AssertEqual(t, slog.Handler(h), h.WithGroup("  "))
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("int", 12)
+	AssertNoError(t, h.WithGroup("group").WithGroup(" ").Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar group.int=12\n", buf.String())
+}
+
+func TestHandler_IndentGroups(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, IndentGroups: true})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+	rec.Add("k", "v", slog.Group("sub", "a", 1))
+	h2 := h.WithAttrs([]slog.Attr{slog.String("a", "b")}).WithGroup("G").WithAttrs([]slog.Attr{slog.String("c", "d")}).WithGroup("H")
+	AssertNoError(t, h2.Handle(context.Background(), rec))
+	AssertEqual(t, "INF foobar a=b\n  G: c=d\n    H: k=v sub.a=1\n", buf.String())
+
+	buf.Reset()
+	AssertNoError(t, h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)))
+	AssertEqual(t, "INF foobar a=b\n  G: c=d\n", buf.String())
+}
+
+func TestHandler_CorrelationKey(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, CorrelationKey: "req"})
+	ctx := context.Background()
+	log := func(h slog.Handler, attrs ...any) {
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)
+		rec.Add(attrs...)
+		AssertNoError(t, h.Handle(ctx, rec))
+	}
+	log(h, "req", 1)
+	log(h, "req", 1, "k", "v")
+	log(h.WithAttrs([]slog.Attr{slog.Int("req", 1)}))
+	log(h, "req", 2)
+	log(h)
+	log(h.WithGroup("g"), "req", 2)
+	AssertEqual(t, "┌ INF foobar req=1\n│ INF foobar req=1 k=v\n│ INF foobar req=1\n┌ INF foobar req=2\n  INF foobar\n  INF foobar g.req=2\n", buf.String())
+
+	buf.Reset()
+	h = NewHandler(&buf, &HandlerOptions{CorrelationKey: "req"})
+	log(h, "req", "abc")
+	log(h, "req", "abc")
+	color := correlationColors[hashString("abc")%uint32(len(correlationColors))]
+	AssertEqual(t, true, strings.HasPrefix(buf.String(), string(color)+"┌ "+string(ResetMod)))
+	AssertEqual(t, true, strings.Contains(buf.String(), "\n"+string(color)+"│ "+string(ResetMod)))
+}
+
+func TestHandler_HideAttrs(t *testing.T) {
+	now := time.Now()
+	rec := slog.NewRecord(now, slog.LevelInfo, "hello", 0)
+	rec.Add("k", "v", slog.Group("g", "a", 1))
+	opts := &HandlerOptions{NoColor: true, HideAttrs: true}
+	AssertEqual(t, now.Format(time.DateTime)+" INF hello\n", string(Render(rec, opts)))
+	opts.HideTime = true
+	AssertEqual(t, "INF hello\n", string(Render(rec, opts)))
+	opts.HideLevel = true
+	AssertEqual(t, "hello\n", string(Render(rec, opts)))
+
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, opts).WithGroup("grp").WithAttrs([]slog.Attr{slog.Int("x", 1)})
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "hello\n", buf.String())
+}
+
+func TestHandler_BareTrueBools(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("cached", true, "stale", false, slog.Group("g", "ok", true))
+	opts := &HandlerOptions{NoColor: true, BareTrueBools: true}
+	AssertEqual(t, "INF msg cached stale=false g.ok\n", string(Render(rec, opts)))
+	opts.BareTrueBools = false
+	AssertEqual(t, "INF msg cached=true stale=false g.ok=true\n", string(Render(rec, opts)))
+}
+
+func TestHandler_PunctuationStyle(t *testing.T) {
+	theme := NewBrightTheme()
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add(slog.Group("g", "k", "v"))
+	key := func(s string) string { return string(theme.AttrKey()) + s + string(ResetMod) }
+	punct := func(s string) string { return string(punctuationStyle(theme)) + s + string(ResetMod) }
+	out := string(Render(rec, &HandlerOptions{Theme: theme, NestedGroups: true}))
+	AssertEqual(t, true, strings.HasSuffix(out, " "+key("g")+punct("=")+punct("{")+key("k")+punct("=")+"v"+punct("}")+"\n"))
+}
+
+func TestHandler_EncodeTimestampEpoch(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	rec := slog.NewRecord(now, slog.LevelInfo, "msg", 0)
+	opts := &HandlerOptions{NoColor: true, EncodeTimestamp: func(buf *Buffer, t time.Time) {
+		buf.AppendInt(t.UnixMilli())
+	}}
+	AssertEqual(t, "1700000000123 INF msg\n", string(Render(rec, opts)))
+}
+
+func TestHandler_SourceSeparatorStyle(t *testing.T) {
+	theme := NewDefaultTheme().(ThemeDef)
+	theme.sourceSeparator = ToANSICode(Faint)
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	buf := new(buffer)
+	newEncoder(HandlerOptions{Theme: theme}, nil).writeSource(buf, pcs[0], cwd)
+	AssertEqual(t, true, strings.HasSuffix(buf.String(), string(ToANSICode(Faint))+" > "+string(ResetMod)))
+	AssertEqual(t, false, strings.Contains(buf.String(), string(theme.AttrKey())))
+}
+
+func TestHandler_Separators(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("a", 1, slog.Group("g", "b", 2))
+	opts := &HandlerOptions{NoColor: true, KeyValueSeparator: ": ", AttrSeparator: "  "}
+	AssertEqual(t, "INF msg  a: 1  g.b: 2\n", string(Render(rec, opts)))
+	opts.NestedGroups = true
+	AssertEqual(t, "INF msg  a: 1  g: {b: 2}\n", string(Render(rec, opts)))
+}
+
+func TestHandler_Plain(t *testing.T) {
+	now := time.Now()
+	rec := slog.NewRecord(now, slog.LevelInfo, "multi\nline", 0)
+	rec.Add("ok", true, "v", "a\nb", slog.Group("g", "k", 1))
+	opts := &HandlerOptions{
+		Plain: true, AddSource: true, NestedGroups: true, BareTrueBools: true,
+		KeyValueSeparator: ": ", HideTime: true, TimeFormat: time.Kitchen,
+	}
+	expected := now.Format(time.Kitchen) + ` INF multi\nline ok=true v=a\nb g.k=1` + "\n"
+	AssertEqual(t, expected, string(Render(rec, opts)))
+
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, opts).WithGroup("grp").WithAttrs([]slog.Attr{slog.Int("x", 1)})
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, now.Format(time.Kitchen)+` INF multi\nline grp.x=1 grp.ok=true grp.v=a\nb grp.g.k=1`+"\n", buf.String())
+
+	buf.Reset()
+	h.(*Handler).SetNoColor(false)
+	rec = slog.NewRecord(now, slog.LevelInfo, "cr\r\nlf", 0)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, now.Format(time.Kitchen)+` INF cr\r\nlf grp.x=1`+"\n", buf.String())
+}
+
+func TestHandler_PlainKeepsOptions(t *testing.T) {
+	buf := bytes.Buffer{}
+	mirror := bytes.Buffer{}
+	code := -1
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{
+		Plain:      true,
+		TimeFormat: "-",
+		LevelRules: map[string]slog.Leveler{"db": slog.LevelWarn},
+		Mirrors:    []Mirror{{W: &mirror, Level: slog.LevelError}},
+		ExitLevel:  slog.LevelError + 4,
+		ExitFunc:   func(c int) { code = c },
+	}))
+	ctx := BufferRecords(context.Background())
+	logger.WithGroup("db").InfoContext(ctx, "hidden")
+	logger.InfoContext(ctx, "first")
+	logger.InfoContext(ctx, "second")
+	AssertNoError(t, FlushRecords(ctx))
+	logger.Error("failed")
+	logger.Log(ctx, slog.LevelError+4, "fatal")
+	AssertEqual(t, 1, code)
+	AssertEqual(t, "- INF first\n- INF second\n- ERR failed\n- ERR+4 fatal\n", buf.String())
+	AssertEqual(t, "- ERR failed\n- ERR+4 fatal\n", mirror.String())
+}
+
+func TestHandler_FormatValue(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("ratio", 0.123456, "name", "x", "boom", 1, slog.Group("g", "ratio", 2.0))
+	opts := &HandlerOptions{NoColor: true, FormatValue: func(key string, v slog.Value) (string, bool) {
+		switch key {
+		case "ratio":
+			return strconv.FormatFloat(v.Float64()*100, 'f', 1, 64) + "%", true
+		case "boom":
+			panic("bad")
+		}
+		return "", false
+	}}
+	AssertEqual(t, "INF msg ratio=12.3% name=x boom=!PANIC formatting value: bad g.ratio=200.0%\n", string(Render(rec, opts)))
+}
+
+func TestHandler_SourceAsAttr(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", pcs[0])
+	rec.Add(slog.Group("g", "a", 1))
+	opts := &HandlerOptions{NoColor: true, AddSource: true, SourceAsAttr: true, NestedGroups: true}
+	AssertEqual(t, fmt.Sprintf("INF msg g={a=1} source=handler_test.go:%d\n", frame.Line), string(Render(rec, opts)))
+}
+
+func TestHandler_GroupThemes(t *testing.T) {
+	theme := NewBrightTheme()
+	sql := NewBrightTheme().(ThemeDef)
+	sql.attrKey = ToANSICode(Faint, Cyan)
+	sql.attrValue = ToANSICode(Faint)
+	opts := &HandlerOptions{Theme: theme, GroupThemes: map[string]Theme{"sql": sql}, HideLevel: true}
+	style := func(s string, m ANSIMod) string {
+		if m == "" {
+			return s
+		}
+		return string(m) + s + string(ResetMod)
+	}
+	attr := func(prefix, k, v string, th Theme) string {
+		p := ""
+		if prefix != "" {
+			p = style(prefix, attrGroupStyle(th)) + style(".", punctuationStyle(th))
+		}
+		return " " + p + style(k, th.AttrKey()) + style("=", punctuationStyle(th)) + style(v, th.AttrValue())
+	}
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("a", "1", slog.Group("sql", "q", "x"))
+	AssertEqual(t, style("msg", theme.Message())+attr("", "a", "1", theme)+attr("sql", "q", "x", sql)+"\n", string(Render(rec, opts)))
+
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, opts).WithGroup("sql")
+	rec = slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("q", "x")
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, style("msg", theme.Message())+attr("sql", "q", "x", sql)+"\n", buf.String())
+}
+
+func TestHandler_KeyStyles(t *testing.T) {
+	theme := NewDefaultTheme()
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("request_id", "abc", "n", 1)
+	out := string(Render(rec, &HandlerOptions{Theme: theme, HideLevel: true, KeyStyles: map[string]ANSIMod{"request_id": ToANSICode(Magenta)}}))
+	kv := "=" + string(ResetMod)
+	AssertEqual(t, true, strings.Contains(out, "request_id"+kv+string(ToANSICode(Magenta))+"abc"+string(ResetMod)+" "))
+	AssertEqual(t, true, strings.HasSuffix(out, "n"+kv+"1\n"))
+
+	out = string(Render(rec, &HandlerOptions{Theme: theme, ColorProfile: Profile16, KeyStyles: map[string]ANSIMod{"request_id": Hex("#ff0000")}}))
+	AssertEqual(t, true, strings.Contains(out, kv+string(ToANSICode(Red))+"abc"))
+}
+
+func TestHandler_NoValueColor(t *testing.T) {
+	theme := NewDefaultTheme()
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("err", errors.New("boom"))
+	out := string(Render(rec, &HandlerOptions{Theme: theme, NoValueColor: true}))
+	AssertEqual(t, true, strings.HasSuffix(out, string(theme.AttrKey())+"err="+string(ResetMod)+"boom\n"))
+	AssertEqual(t, true, strings.HasPrefix(out, string(theme.LevelInfo())))
+}
+
+func TestHandler_RelativeTimes(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("created", time.Now().Add(-3*time.Hour-time.Minute), "expires", time.Now().Add(49*time.Hour))
+	AssertEqual(t, "INF msg created=3h ago expires=in 2d\n", string(Render(rec, &HandlerOptions{NoColor: true, RelativeTimes: true})))
+}
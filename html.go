@@ -0,0 +1,218 @@
+package console
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// htmlColors are the CSS colors of the 8 basic ANSI colors,
+// followed by their bright variants.
+var htmlColors = [16]string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// HTMLWriter converts the colored output of a handler into HTML, replacing
+// the ANSI escape sequences of the theme with <span> elements styled
+// with the same colors, so that logs can be displayed in a browser, like
+// in CI reports or bug trackers. The text is HTML escaped. The output is
+// meant to be enclosed in a <pre> element.
+//
+//	fmt.Fprintln(file, "<pre>")
+//	logger := slog.New(console.NewHandler(console.NewHTMLWriter(file), nil))
+type HTMLWriter struct {
+	w       io.Writer
+	buf     []byte
+	pending []byte // Incomplete escape sequence at the end of the last write
+	style   htmlStyle
+	open    bool // Whether a span is open
+}
+
+// NewHTMLWriter creates an HTMLWriter writing to w.
+func NewHTMLWriter(w io.Writer) *HTMLWriter {
+	return &HTMLWriter{w: w}
+}
+
+type htmlStyle struct {
+	bold, faint, italic, underline, strike bool
+	color                                  string
+}
+
+// Write converts p to HTML and writes it to the underlying writer.
+func (h *HTMLWriter) Write(p []byte) (int, error) {
+	b := p
+	if len(h.pending) > 0 {
+		b = append(h.pending, p...)
+		h.pending = nil
+	}
+	h.buf = h.buf[:0]
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\x1b')
+		if i < 0 {
+			h.appendText(b)
+			break
+		}
+		h.appendText(b[:i])
+		b = b[i:]
+		if len(b) < 2 {
+			// The sequence may continue in the next write
+			h.pending = append([]byte(nil), b...)
+			break
+		}
+		if b[1] != '[' {
+			// Not a CSI sequence, drop the lone ESC
+			b = b[1:]
+			continue
+		}
+		// Parameter and intermediate bytes, up to the final byte
+		j := 2
+		for j < len(b) && b[j] >= 0x20 && b[j] <= 0x3f {
+			j++
+		}
+		switch {
+		case j == len(b):
+			h.pending = append([]byte(nil), b...)
+			b = nil
+		case b[j] < 0x40 || b[j] > 0x7e:
+			// Malformed sequence, drop its introducer
+			b = b[2:]
+		default:
+			if b[j] == 'm' {
+				h.applySGR(string(b[2:j]))
+			}
+			// Other sequences, like erasing the line, are ignored
+			b = b[j+1:]
+		}
+	}
+	if _, err := h.w.Write(h.buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the text of an incomplete escape sequence ending the
+// output, if any, and closes the span left open.
+func (h *HTMLWriter) Close() error {
+	h.buf = h.buf[:0]
+	if len(h.pending) > 0 {
+		h.appendText(h.pending[1:]) // Without the ESC
+		h.pending = nil
+	}
+	if h.open {
+		h.buf = append(h.buf, "</span>"...)
+		h.open = false
+	}
+	if len(h.buf) == 0 {
+		return nil
+	}
+	_, err := h.w.Write(h.buf)
+	return err
+}
+
+// applySGR applies the parameters of a "Select Graphic Rendition"
+// sequence, like "1;31", to the current style.
+func (h *HTMLWriter) applySGR(params string) {
+	if h.open {
+		h.buf = append(h.buf, "</span>"...)
+		h.open = false
+	}
+	ps := strings.Split(params, ";")
+	for i := 0; i < len(ps); i++ {
+		n, err := strconv.Atoi(ps[i])
+		if err != nil && ps[i] != "" {
+			continue
+		}
+		switch {
+		case n == 38 && i+4 < len(ps) && ps[i+1] == "2":
+			h.style.color = rgbCSS(ps[i+2], ps[i+3], ps[i+4])
+			i += 4
+		case n == 38 && i+2 < len(ps) && ps[i+1] == "5":
+			c, _ := strconv.ParseUint(ps[i+2], 10, 8)
+			r, g, b := xterm256RGB(uint8(c))
+			h.style.color = string(appendHexColor(nil, r, g, b))
+			i += 2
+		case n == Reset:
+			h.style = htmlStyle{}
+		case n == Bold:
+			h.style.bold = true
+		case n == Faint:
+			h.style.faint = true
+		case n == Italic:
+			h.style.italic = true
+		case n == Underline:
+			h.style.underline = true
+		case n == CrossedOut:
+			h.style.strike = true
+		case n >= Black && n <= Gray:
+			h.style.color = htmlColors[n-Black]
+		case n >= BrightBlack && n <= White:
+			h.style.color = htmlColors[8+n-BrightBlack]
+		}
+	}
+}
+
+// appendText appends the HTML escaped text b, within a span styled
+// with the current style.
+func (h *HTMLWriter) appendText(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	if !h.open {
+		if css := h.style.css(); css != "" {
+			h.buf = append(h.buf, `<span style="`...)
+			h.buf = append(h.buf, css...)
+			h.buf = append(h.buf, `">`...)
+			h.open = true
+		}
+	}
+	h.buf = append(h.buf, html.EscapeString(string(b))...)
+}
+
+// rgbCSS returns the CSS color of the decimal components r, g and b.
+func rgbCSS(r, g, b string) string {
+	var c [3]uint8
+	for i, s := range []string{r, g, b} {
+		n, _ := strconv.ParseUint(s, 10, 8)
+		c[i] = uint8(n)
+	}
+	return string(appendHexColor(nil, c[0], c[1], c[2]))
+}
+
+// appendHexColor appends the color r, g, b in CSS hexadecimal
+// notation, like "#ff8800".
+func appendHexColor(dst []byte, r, g, b uint8) []byte {
+	const digits = "0123456789abcdef"
+	dst = append(dst, '#')
+	for _, c := range []uint8{r, g, b} {
+		dst = append(dst, digits[c>>4], digits[c&0xf])
+	}
+	return dst
+}
+
+func (s htmlStyle) css() string {
+	var sb strings.Builder
+	if s.color != "" {
+		sb.WriteString("color:" + s.color + ";")
+	}
+	if s.bold {
+		sb.WriteString("font-weight:bold;")
+	}
+	if s.faint {
+		sb.WriteString("opacity:0.7;")
+	}
+	if s.italic {
+		sb.WriteString("font-style:italic;")
+	}
+	switch {
+	case s.underline && s.strike:
+		sb.WriteString("text-decoration:underline line-through;")
+	case s.underline:
+		sb.WriteString("text-decoration:underline;")
+	case s.strike:
+		sb.WriteString("text-decoration:line-through;")
+	}
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHTMLWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewHTMLWriter(&buf)
+	_, err := w.Write([]byte("\x1b[1;31mERR\x1b[0m a<b \x1b[3"))
+	AssertNoError(t, err)
+	_, err = w.Write([]byte("6mk=\x1b[0m&\x1b[4;9;92mx"))
+	AssertNoError(t, err)
+	AssertNoError(t, w.Close())
+	AssertEqual(t, `<span style="color:#cd3131;font-weight:bold;">ERR</span> a&lt;b `+
+		`<span style="color:#11a8cd;">k=</span>&amp;`+
+		`<span style="color:#23d18b;text-decoration:underline line-through;">x</span>`, buf.String())
+}
+
+func TestHTMLWriter_Handler(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(NewHTMLWriter(&buf), &HandlerOptions{Theme: NewDefaultTheme()})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "a & b", 0)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, `<span style="color:#0dbc79;">INF</span> <span style="font-weight:bold;">a &amp; b</span>`+"\n", buf.String())
+}
+
+func TestHTMLWriter_ExtendedColors(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewHTMLWriter(&buf)
+	_, err := w.Write([]byte(ToANSICode(Bold) + Hex("#ff8800") + "warm" + ResetMod + Color256(208) + "x" + ResetMod))
+	AssertNoError(t, err)
+	AssertEqual(t, `<span style="color:#ff8800;font-weight:bold;">warm</span><span style="color:#ff8700;">x</span>`, buf.String())
+}
+
+func TestHTMLWriter_OtherSequences(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewHTMLWriter(&buf)
+	_, err := w.Write([]byte("a\x1b[0Kb \x1bc \x1b[31mred\x1b[0m d\x1b"))
+	AssertNoError(t, err)
+	AssertEqual(t, `ab c <span style="color:#cd3131;">red</span> d`, buf.String())
+	_, err = w.Write([]byte("[2K e \x1b["))
+	AssertNoError(t, err)
+	AssertNoError(t, w.Close())
+	AssertEqual(t, `ab c <span style="color:#cd3131;">red</span> d e [`, buf.String())
+}
@@ -0,0 +1,20 @@
+package console
+
+import (
+	"os"
+	"syscall"
+)
+
+// isatty reports whether f is a console.
+func isatty(f *os.File) bool {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+	var mode uint32
+	var modeErr error
+	err = rc.Control(func(fd uintptr) {
+		modeErr = syscall.GetConsoleMode(syscall.Handle(fd), &mode)
+	})
+	return err == nil && modeErr == nil
+}
@@ -0,0 +1,149 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Extra levels, beside the standard ones of slog. They are rendered as
+// a standard level with a delta, like "DBG-4" for LevelTrace, unless they
+// are given a label with HandlerOptions.LevelNames, and a style with
+// HandlerOptions.LevelStyles:
+//
+//	opts := &console.HandlerOptions{
+//		LevelNames:  map[slog.Leveler]string{console.LevelTrace: "TRC", console.LevelFatal: "FTL"},
+//		LevelStyles: map[slog.Leveler]console.ANSIMod{console.LevelFatal: console.ToANSICode(console.Bold, console.Magenta)},
+//	}
+const (
+	LevelTrace  slog.Level = -8
+	LevelNotice slog.Level = 2
+	LevelFatal  slog.Level = 12
+)
+
+// LevelDelta selects how the levels between the standard ones, like
+// slog.LevelInfo+2, are rendered.
+type LevelDelta int
+
+const (
+	// LevelDeltaShow renders the standard level below, with the delta
+	// as a suffix, like "INF+2".
+	LevelDeltaShow LevelDelta = iota
+	// LevelDeltaHide renders the standard level below only, like "INF".
+	LevelDeltaHide
+	// LevelDeltaNumeric renders the numeric value of all levels, like
+	// "-4" for slog.LevelDebug and "2" for slog.LevelInfo+2.
+	LevelDeltaNumeric
+)
+
+// levelsByName are the level names accepted by LevelFromEnv, in lower case.
+var levelsByName = map[string]slog.Level{
+	"trace":   LevelTrace,
+	"debug":   slog.LevelDebug,
+	"dbg":     slog.LevelDebug,
+	"info":    slog.LevelInfo,
+	"inf":     slog.LevelInfo,
+	"notice":  LevelNotice,
+	"warn":    slog.LevelWarn,
+	"warning": slog.LevelWarn,
+	"wrn":     slog.LevelWarn,
+	"error":   slog.LevelError,
+	"err":     slog.LevelError,
+	"fatal":   LevelFatal,
+}
+
+// LevelFromEnv returns the level set by the environment variable key,
+// or def if it is unset or invalid. The level is either a name, like
+// "debug", "info", "warn" or "error", case insensitive, optionally
+// followed by an offset, like "debug-4" or "INFO+2", or a number, like "-4":
+//
+//	opts := &console.HandlerOptions{Level: console.LevelFromEnv("LOG_LEVEL", slog.LevelInfo)}
+func LevelFromEnv(key string, def slog.Level) slog.Level {
+	if l, ok := parseLevelName(os.Getenv(key)); ok {
+		return l
+	}
+	return def
+}
+
+// parseLevelName parses a level name, with an optional offset, or a number.
+func parseLevelName(s string) (slog.Level, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), true
+	}
+	return parseLevelLabel(s, levelsByName)
+}
+
+// parseLevelLabel parses a level label followed by an optional offset,
+// like "INF+2", the label being looked up in labels.
+func parseLevelLabel(s string, labels map[string]slog.Level) (slog.Level, bool) {
+	label, delta := s, 0
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		d, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return 0, false
+		}
+		label, delta = s[:i], d
+	}
+	l, ok := labels[label]
+	if !ok {
+		return 0, false
+	}
+	return l + slog.Level(delta), true
+}
+
+// minLevel returns the minimum level of records written by h.
+func (h *Handler) minLevel() slog.Level {
+	if h.rule != nil {
+		return h.rule.Level()
+	}
+	return h.level.get().Level()
+}
+
+// levelEnabled reports whether records at level l logged with ctx are
+// written by h, according to its minimum level and EnabledFunc.
+func (h *Handler) levelEnabled(ctx context.Context, l slog.Level) bool {
+	if l >= h.minLevel() {
+		return true
+	}
+	if h.opts.EnabledFunc != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		return h.opts.EnabledFunc(ctx, l)
+	}
+	return false
+}
+
+// levelRule returns the level of the LevelRules matching a handler
+// in the groups g, with the context attributes attrs, or nil.
+func (o *HandlerOptions) levelRule(g groups, attrs []groupedAttr) slog.Leveler {
+	if len(o.LevelRules) == 0 {
+		return nil
+	}
+	path := strings.Join(g.names, ".")
+	var best slog.Leveler
+	bestLen := -1
+	for rule, l := range o.LevelRules {
+		if strings.Contains(rule, "=") {
+			continue
+		}
+		if (path == rule || strings.HasPrefix(path, rule+".")) && len(rule) > bestLen {
+			best, bestLen = l, len(rule)
+		}
+	}
+	if best != nil {
+		return best
+	}
+	// The last matching attribute wins
+	for i := len(attrs) - 1; i >= 0; i-- {
+		a := attrs[i]
+		key := string(a.groups.prefix) + a.attr.Key
+		if l, ok := o.LevelRules[key+"="+a.attr.Value.Resolve().String()]; ok {
+			return l
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandler_LevelRules(t *testing.T) {
+	buf := bytes.Buffer{}
+	warn := new(slog.LevelVar)
+	warn.Set(slog.LevelWarn)
+	h := NewHandler(&buf, &HandlerOptions{
+		NoColor:  true,
+		HideTime: true,
+		LevelRules: map[string]slog.Leveler{
+			"db":             slog.LevelDebug,
+			"db.pool":        slog.LevelError,
+			"component=http": warn,
+		},
+	})
+	ctx := context.Background()
+	for _, tc := range []struct {
+		handler slog.Handler
+		level   slog.Level
+	}{
+		{h, slog.LevelInfo},
+		{h.WithGroup("db"), slog.LevelDebug},
+		{h.WithGroup("db").WithGroup("query"), slog.LevelDebug},
+		{h.WithGroup("dbx"), slog.LevelInfo},
+		{h.WithGroup("db").WithGroup("pool"), slog.LevelError},
+		{h.WithAttrs([]slog.Attr{slog.String("component", "http")}), slog.LevelWarn},
+		{h.WithAttrs([]slog.Attr{slog.String("component", "http")}).WithGroup("db"), slog.LevelDebug},
+		{h.WithAttrs([]slog.Attr{slog.String("component", "grpc")}), slog.LevelInfo},
+		{h.WithGroup("g").WithAttrs([]slog.Attr{slog.String("component", "http")}), slog.LevelInfo},
+		{h.WithOptions(func(o *HandlerOptions) {}).WithGroup("db"), slog.LevelDebug},
+	} {
+		AssertEqual(t, false, tc.handler.Enabled(ctx, tc.level-1))
+		AssertEqual(t, true, tc.handler.Enabled(ctx, tc.level))
+		AssertEqual(t, tc.level, tc.handler.(*Handler).Level())
+	}
+
+	warn.Set(slog.LevelError)
+	AssertEqual(t, false, h.WithAttrs([]slog.Attr{slog.String("component", "http")}).Enabled(ctx, slog.LevelWarn))
+
+	h.SetLevel(slog.LevelError)
+	AssertEqual(t, true, h.WithGroup("db").Enabled(ctx, slog.LevelDebug))
+	AssertEqual(t, false, h.Enabled(ctx, slog.LevelWarn))
+}
+
+type debugKey struct{}
+
+func TestHandler_EnabledFunc(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{
+		NoColor:  true,
+		HideTime: true,
+		EnabledFunc: func(ctx context.Context, l slog.Level) bool {
+			return ctx.Value(debugKey{}) != nil
+		},
+	})
+	logger := slog.New(h)
+	debugCtx := context.WithValue(context.Background(), debugKey{}, true)
+	logger.DebugContext(context.Background(), "hidden")
+	logger.DebugContext(debugCtx, "shown")
+	logger.InfoContext(context.Background(), "info")
+	AssertEqual(t, "DBG shown\nINF info\n", buf.String())
+
+	buf.Reset()
+	mirror := bytes.Buffer{}
+	h = h.WithOptions(func(o *HandlerOptions) { o.Mirrors = []Mirror{{W: &mirror, Level: slog.LevelDebug}} })
+	AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelDebug, "hidden", 0)))
+	AssertNoError(t, h.Handle(debugCtx, slog.NewRecord(time.Time{}, slog.LevelDebug, "shown", 0)))
+	AssertEqual(t, "DBG shown\n", buf.String())
+	AssertEqual(t, "DBG hidden\nDBG shown\n", mirror.String())
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected slog.Level
+	}{
+		{"", slog.LevelWarn},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{" warning ", slog.LevelWarn},
+		{"Error", slog.LevelError},
+		{"trace", LevelTrace},
+		{"debug-2", slog.LevelDebug - 2},
+		{"info+2", slog.LevelInfo + 2},
+		{"-4", slog.LevelDebug},
+		{"12", LevelFatal},
+		{"verbose", slog.LevelWarn},
+		{"info+x", slog.LevelWarn},
+	} {
+		t.Setenv("LOG_LEVEL", tc.value)
+		AssertEqual(t, tc.expected, LevelFromEnv("LOG_LEVEL", slog.LevelWarn))
+	}
+}
@@ -0,0 +1,173 @@
+package console
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Metrics collects counters about the activity of the handlers it is given
+// to through HandlerOptions.Metrics. All methods are safe for concurrent use.
+//
+// Metrics implements expvar.Var, so it can be published with expvar.Publish.
+// Other monitoring systems can poll Snapshot.
+type Metrics struct {
+	records     [4]atomic.Uint64 // Indexed by levelIndex
+	bytes       atomic.Uint64
+	writeErrors atomic.Uint64
+	dropped     atomic.Uint64
+	written     atomic.Uint64 // Records written to the main output
+	lastErr     atomic.Pointer[error]
+}
+
+// MetricsSnapshot is a point in time copy of the counters of a Metrics.
+type MetricsSnapshot struct {
+	// Records handled, by level. Levels are rounded down to the nearest
+	// standard level, and levels below slog.LevelDebug are counted as debug.
+	RecordsDebug uint64 `json:"records_debug"`
+	RecordsInfo  uint64 `json:"records_info"`
+	RecordsWarn  uint64 `json:"records_warn"`
+	RecordsError uint64 `json:"records_error"`
+	// BytesWritten is the number of bytes successfully written.
+	BytesWritten uint64 `json:"bytes_written"`
+	// WriteErrors is the number of failed writes.
+	WriteErrors uint64 `json:"write_errors"`
+	// Dropped is the number of records which were handled but not
+	// written, because the handler is disabled or the write failed.
+	Dropped uint64 `json:"dropped"`
+}
+
+// Records returns the number of records handled at the standard level l
+// belongs to.
+func (m *Metrics) Records(l slog.Level) uint64 {
+	return m.records[levelIndex(l)].Load()
+}
+
+// Snapshot returns the current value of all the counters.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		RecordsDebug: m.records[0].Load(),
+		RecordsInfo:  m.records[1].Load(),
+		RecordsWarn:  m.records[2].Load(),
+		RecordsError: m.records[3].Load(),
+		BytesWritten: m.bytes.Load(),
+		WriteErrors:  m.writeErrors.Load(),
+		Dropped:      m.dropped.Load(),
+	}
+}
+
+// LastWriteError returns the error of the last failed write, or nil.
+func (m *Metrics) LastWriteError() error {
+	if err := m.lastErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// String implements expvar.Var, returning the snapshot as a JSON object.
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.Snapshot())
+	return string(b)
+}
+
+// The following methods do nothing on a nil Metrics, used when
+// statistics are not collected.
+
+func (m *Metrics) recordHandled(l slog.Level) {
+	if m != nil {
+		m.records[levelIndex(l)].Add(1)
+	}
+}
+
+func (m *Metrics) recordDropped() {
+	if m != nil {
+		m.dropped.Add(1)
+	}
+}
+
+// recordWritten counts a record written to the main output of a
+// handler, with n bytes, or dropped if err is not nil.
+func (m *Metrics) recordWritten(n int64, err error) {
+	if m == nil {
+		return
+	}
+	m.bytes.Add(uint64(n))
+	if err != nil {
+		m.recordWriteError(err)
+		m.dropped.Add(1)
+		return
+	}
+	m.written.Add(1)
+}
+
+// recordWriteError counts a failed write, to the main output or a mirror.
+func (m *Metrics) recordWriteError(err error) {
+	if m == nil {
+		return
+	}
+	last := err // Don't let err escape when there is no error
+	m.lastErr.Store(&last)
+	m.writeErrors.Add(1)
+}
+
+// Stats reports the health of the output of a handler.
+type Stats struct {
+	// Records is the number of records written to the output, not
+	// counting the ones written only to mirrors, or still buffered by
+	// BufferRecords.
+	Records uint64
+	// Bytes is the number of bytes successfully written.
+	Bytes uint64
+	// Dropped is the number of records which were handled but not
+	// written, because the handler is disabled or the write failed.
+	Dropped uint64
+	// LastWriteError is the error of the last failed write, or nil.
+	LastWriteError error
+}
+
+// Stats returns statistics about the records written by h. They are
+// collected with HandlerOptions.Metrics if set, so they account for all
+// the handlers sharing it. Otherwise, they are only collected with
+// HandlerOptions.CollectStats, and shared by h and the handlers derived
+// from it. Stats returns zero statistics if none are collected.
+func (h *Handler) Stats() Stats {
+	m := h.stats
+	if m == nil {
+		return Stats{}
+	}
+	return Stats{
+		Records:        m.written.Load(),
+		Bytes:          m.bytes.Load(),
+		Dropped:        m.dropped.Load(),
+		LastWriteError: m.LastWriteError(),
+	}
+}
+
+// newStats returns the metrics collecting the statistics of a handler with
+// the options o, derived from a handler using prev if not nil. It returns
+// nil if no statistics are collected.
+func newStats(o HandlerOptions, prev *Metrics) *Metrics {
+	switch {
+	case o.Metrics != nil:
+		return o.Metrics
+	case prev != nil:
+		return prev
+	case o.CollectStats:
+		return new(Metrics)
+	default:
+		return nil
+	}
+}
+
+func levelIndex(l slog.Level) int {
+	switch {
+	case l >= slog.LevelError:
+		return 3
+	case l >= slog.LevelWarn:
+		return 2
+	case l >= slog.LevelInfo:
+		return 1
+	default:
+		return 0
+	}
+}
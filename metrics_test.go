@@ -0,0 +1,93 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := new(Metrics)
+	fail := false
+	w := writerFunc(func(b []byte) (int, error) {
+		if fail {
+			return 0, errors.New("nope")
+		}
+		return len(b), nil
+	})
+	h := NewHandler(w, &HandlerOptions{NoColor: true, Level: slog.LevelDebug - 4, Metrics: m})
+	ctx := context.Background()
+	for _, l := range []slog.Level{slog.LevelDebug - 4, slog.LevelDebug, slog.LevelInfo, slog.LevelWarn + 1, slog.LevelError} {
+		AssertNoError(t, h.Handle(ctx, slog.NewRecord(time.Time{}, l, "foobar", 0)))
+	}
+	AssertEqual(t, uint64(2), m.Records(slog.LevelDebug))
+	AssertEqual(t, uint64(1), m.Records(slog.LevelInfo))
+	AssertEqual(t, uint64(1), m.Records(slog.LevelWarn))
+	AssertEqual(t, uint64(1), m.Records(slog.LevelError+4))
+	AssertEqual(t, uint64(len("DBG-4 foobar\nDBG foobar\nINF foobar\nWRN+1 foobar\nERR foobar\n")), m.Snapshot().BytesWritten)
+
+	fail = true
+	AssertError(t, h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)))
+	h.WithOptions(func(o *HandlerOptions) { o.Disabled = true }).Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0))
+
+	s := m.Snapshot()
+	AssertEqual(t, uint64(3), s.RecordsInfo)
+	AssertEqual(t, uint64(1), s.WriteErrors)
+	AssertEqual(t, uint64(2), s.Dropped)
+
+	var decoded MetricsSnapshot
+	AssertNoError(t, json.Unmarshal([]byte(m.String()), &decoded))
+	AssertEqual(t, s, decoded)
+}
+
+func TestHandler_Stats(t *testing.T) {
+	fail := false
+	w := writerFunc(func(b []byte) (int, error) {
+		if fail {
+			return 0, errors.New("nope")
+		}
+		return len(b), nil
+	})
+	AssertEqual(t, Stats{}, NewHandler(w, &HandlerOptions{NoColor: true}).Stats())
+
+	h := NewHandler(w, &HandlerOptions{NoColor: true, CollectStats: true})
+	ctx := context.Background()
+	AssertNoError(t, h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0)))
+	AssertNoError(t, h.WithGroup("g").Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelWarn, "foobar", 0)))
+	AssertEqual(t, Stats{Records: 2, Bytes: uint64(len("INF foobar\nWRN foobar\n"))}, h.Stats())
+
+	fail = true
+	err := h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "foobar", 0))
+	AssertError(t, err)
+	s := h.Stats()
+	AssertEqual(t, uint64(2), s.Records)
+	AssertEqual(t, uint64(1), s.Dropped)
+	AssertEqual(t, err, s.LastWriteError)
+
+	m := new(Metrics)
+	AssertEqual(t, Stats{}, h.WithOptions(func(o *HandlerOptions) { o.Metrics = m }).Stats())
+}
+
+func TestHandler_StatsWrittenOnly(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{
+		NoColor:      true,
+		Level:        slog.LevelWarn,
+		Mirrors:      []Mirror{{W: io.Discard, Level: slog.LevelDebug}},
+		CollectStats: true,
+	})
+	logger := slog.New(h)
+	logger.Info("mirror only")
+	AssertEqual(t, uint64(0), h.Stats().Records)
+
+	ctx := BufferRecords(context.Background())
+	logger.WarnContext(ctx, "buffered")
+	AssertEqual(t, uint64(0), h.Stats().Records)
+	AssertNoError(t, FlushRecords(ctx))
+	AssertEqual(t, Stats{Records: 1, Bytes: uint64(buf.Len())}, h.Stats())
+}
@@ -0,0 +1,77 @@
+package console
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Mirror is a secondary destination records are written to, in addition
+// to the handler's output, with its own minimum level. For instance, a
+// handler writing to the terminal at info level can mirror debug records
+// to a file. Wrap the writer with AutoColor to strip colors from files
+// and pipes.
+type Mirror struct {
+	W io.Writer
+	// Level is the minimum level of the records written to W.
+	// If nil, the level of the handler is used.
+	Level slog.Leveler
+}
+
+// mirror is a Mirror whose writes are serialized.
+type mirror struct {
+	out   *output
+	level slog.Leveler
+}
+
+// newMirrors creates the mirrors for ms, reusing the outputs of the
+// previous mirrors having the same writer.
+func newMirrors(ms []Mirror, prev []mirror) []mirror {
+	if len(ms) == 0 {
+		return nil
+	}
+	mirrors := make([]mirror, len(ms))
+	for i, m := range ms {
+		mirrors[i].level = m.Level
+		for _, p := range prev {
+			if p.out.w == m.W {
+				mirrors[i].out = p.out
+				break
+			}
+		}
+		if mirrors[i].out == nil {
+			mirrors[i].out = newOutput(m.W)
+		}
+	}
+	return mirrors
+}
+
+// mirrorEnabled reports whether a mirror of h accepts records at level l.
+func (h *Handler) mirrorEnabled(l slog.Level) bool {
+	for _, m := range h.mirrors {
+		if h.mirrorAccepts(m, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) mirrorAccepts(m mirror, l slog.Level) bool {
+	if m.level == nil {
+		return l >= h.minLevel()
+	}
+	return l >= m.level.Level()
+}
+
+// writeMirrors writes the rendered record b at level l to the mirrors accepting it.
+func (h *Handler) writeMirrors(b []byte, l slog.Level) error {
+	var err error
+	for _, m := range h.mirrors {
+		if !h.mirrorAccepts(m, l) {
+			continue
+		}
+		if _, e := m.out.Write(b); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
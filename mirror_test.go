@@ -0,0 +1,45 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandler_Mirrors(t *testing.T) {
+	out, file, errs := bytes.Buffer{}, bytes.Buffer{}, bytes.Buffer{}
+	h := NewHandler(&out, &HandlerOptions{
+		NoColor:  true,
+		HideTime: true,
+		Mirrors: []Mirror{
+			{W: &file, Level: slog.LevelDebug},
+			{W: &errs, Level: slog.LevelError},
+		},
+	})
+	ctx := context.Background()
+	AssertEqual(t, true, h.Enabled(ctx, slog.LevelDebug))
+	AssertEqual(t, false, h.Enabled(ctx, slog.LevelDebug-1))
+
+	logger := slog.New(h.WithAttrs([]slog.Attr{slog.Int("a", 1)}))
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+	AssertEqual(t, "INF info a=1\nERR error a=1\n", out.String())
+	AssertEqual(t, "DBG debug a=1\nINF info a=1\nERR error a=1\n", file.String())
+	AssertEqual(t, "ERR error a=1\n", errs.String())
+
+	// Mirrors still receive records when the main output is discarded
+	file.Reset()
+	h.SetOutput(io.Discard)
+	AssertNoError(t, h.Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelInfo, "discarded", 0)))
+	AssertEqual(t, "INF discarded\n", file.String())
+
+	// Mirrors without level follow the handler's
+	other := bytes.Buffer{}
+	h2 := h.WithOptions(func(o *HandlerOptions) { o.Mirrors = []Mirror{{W: &other}} })
+	AssertEqual(t, false, h2.Enabled(ctx, slog.LevelDebug))
+	AssertEqual(t, true, h2.Enabled(ctx, slog.LevelInfo))
+}
@@ -0,0 +1,137 @@
+package console
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// FloatFormat is the notation used to render float values.
+type FloatFormat int
+
+const (
+	// FloatAuto uses the shortest representation, in decimal notation
+	// for moderate exponents and in scientific notation for large ones,
+	// like strconv.FormatFloat with the 'g' format.
+	FloatAuto FloatFormat = iota
+	// FloatDecimal always uses the decimal notation, like "0.000012".
+	FloatDecimal
+	// FloatScientific always uses the scientific notation, like "1.2e-05".
+	FloatScientific
+)
+
+func (f FloatFormat) append(dst []byte, v float64) []byte {
+	switch f {
+	case FloatDecimal:
+		return strconv.AppendFloat(dst, v, 'f', -1, 64)
+	case FloatScientific:
+		return strconv.AppendFloat(dst, v, 'e', -1, 64)
+	default:
+		return strconv.AppendFloat(dst, v, 'g', -1, 64)
+	}
+}
+
+// humanized reports whether the integer values of the attribute key are humanized.
+func (e *encoder) humanized(key string) bool {
+	return e.opts.HumanizeNumbers || slices.Contains(e.opts.HumanizeKeys, key)
+}
+
+// writeHumanizedNumber writes the integer value of the attribute key in
+// a short form, like "1.2k", if it must be humanized. It reports whether
+// the value was written.
+func (e *encoder) writeHumanizedNumber(buf *buffer, key string, value slog.Value) bool {
+	var n float64
+	switch value.Kind() {
+	case slog.KindInt64:
+		n = float64(value.Int64())
+	case slog.KindUint64:
+		n = float64(value.Uint64())
+	default:
+		return false
+	}
+	if !e.humanized(key) {
+		return false
+	}
+	e.withColor(buf, e.opts.Theme.AttrValue(), func() {
+		*buf = appendHumanized(*buf, n)
+	})
+	return true
+}
+
+// appendHumanized appends n with a metric suffix and one decimal,
+// like "1.2k", "3.4M" or "5.6B". Numbers below 1000 are appended as is.
+func appendHumanized(dst []byte, n float64) []byte {
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < 1000 {
+		return strconv.AppendFloat(dst, n, 'f', -1, 64)
+	}
+	const suffixes = "kMBT"
+	i := 0
+	for abs /= 1000; abs >= 999.95 && i < len(suffixes)-1; abs /= 1000 {
+		n /= 1000
+		i++
+	}
+	n /= 1000
+	dst = strconv.AppendFloat(dst, n, 'f', 1, 64)
+	return append(dst, suffixes[i])
+}
+
+// writeGroupedDigits writes the integer value of the attribute key with its
+// digits grouped by thousands, like "1,234,567", if the key is one of
+// GroupDigitsKeys. It reports whether the value was written.
+func (e *encoder) writeGroupedDigits(buf *buffer, key string, value slog.Value) bool {
+	if len(e.opts.GroupDigitsKeys) == 0 || !slices.Contains(e.opts.GroupDigitsKeys, key) {
+		return false
+	}
+	var digits [24]byte
+	var b []byte
+	switch value.Kind() {
+	case slog.KindInt64:
+		b = strconv.AppendInt(digits[:0], value.Int64(), 10)
+	case slog.KindUint64:
+		b = strconv.AppendUint(digits[:0], value.Uint64(), 10)
+	default:
+		return false
+	}
+	sep := e.opts.DigitSeparator
+	if sep == "" {
+		sep = ","
+	}
+	e.withColor(buf, e.opts.Theme.AttrValue(), func() {
+		*buf = appendGroupedDigits(*buf, b, sep)
+	})
+	return true
+}
+
+// appendGroupedDigits appends the decimal number n, optionally signed,
+// with sep between each group of 3 digits.
+func appendGroupedDigits(dst, n []byte, sep string) []byte {
+	if len(n) > 0 && n[0] == '-' {
+		dst = append(dst, '-')
+		n = n[1:]
+	}
+	for i, d := range n {
+		if i > 0 && (len(n)-i)%3 == 0 {
+			dst = append(dst, sep...)
+		}
+		dst = append(dst, d)
+	}
+	return dst
+}
+
+// writeUnit writes the unit of the attribute key from Units,
+// if it's a number.
+func (e *encoder) writeUnit(buf *buffer, key string, value slog.Value) {
+	if e.opts.Units == nil {
+		return
+	}
+	switch value.Kind() {
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64:
+		if unit, ok := e.opts.Units[key]; ok {
+			e.writeColoredString(buf, unit, e.opts.Theme.AttrValue())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package console
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAppendHumanized(t *testing.T) {
+	for _, tc := range []struct {
+		n        float64
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{-999, "-999"},
+		{1000, "1.0k"},
+		{1234, "1.2k"},
+		{-1234, "-1.2k"},
+		{999_949, "999.9k"},
+		{999_960, "1.0M"},
+		{3_400_000, "3.4M"},
+		{5_600_000_000, "5.6B"},
+		{7e15, "7000.0T"},
+	} {
+		AssertEqual(t, tc.expected, string(appendHumanized(nil, tc.n)))
+	}
+}
+
+func TestHandler_Humanize(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("hits", 1234567, "size", uint64(2500), "id", 123456, "ratio", 1234.5)
+	AssertEqual(t, "INF msg hits=1.2M size=2.5k id=123456 ratio=1234.5\n",
+		string(Render(rec, &HandlerOptions{NoColor: true, HumanizeKeys: []string{"hits", "size"}})))
+	AssertEqual(t, "INF msg hits=1.2M size=2.5k id=123.5k ratio=1234.5\n",
+		string(Render(rec, &HandlerOptions{NoColor: true, HumanizeNumbers: true})))
+}
+
+func TestFloatFormat(t *testing.T) {
+	for _, tc := range []struct {
+		v                      float64
+		auto, decimal, science string
+	}{
+		{3.14, "3.14", "3.14", "3.14e+00"},
+		{0.000012, "1.2e-05", "0.000012", "1.2e-05"},
+		{1.5e21, "1.5e+21", "1500000000000000000000", "1.5e+21"},
+	} {
+		AssertEqual(t, tc.auto, string(FloatAuto.append(nil, tc.v)))
+		AssertEqual(t, tc.decimal, string(FloatDecimal.append(nil, tc.v)))
+		AssertEqual(t, tc.science, string(FloatScientific.append(nil, tc.v)))
+	}
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("f", 0.000012)
+	AssertEqual(t, "INF msg f=0.000012\n", string(Render(rec, &HandlerOptions{NoColor: true, FloatFormat: FloatDecimal})))
+}
+
+func TestAppendGroupedDigits(t *testing.T) {
+	for _, tc := range []struct {
+		n, expected string
+	}{
+		{"0", "0"},
+		{"123", "123"},
+		{"1234", "1,234"},
+		{"-1234567", "-1,234,567"},
+		{"123456", "123,456"},
+	} {
+		AssertEqual(t, tc.expected, string(appendGroupedDigits(nil, []byte(tc.n), ",")))
+	}
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("count", 1234567, "total", uint64(9876543), "id", 1234567)
+	opts := &HandlerOptions{NoColor: true, GroupDigitsKeys: []string{"count", "total"}}
+	AssertEqual(t, "INF msg count=1,234,567 total=9,876,543 id=1234567\n", string(Render(rec, opts)))
+	opts.DigitSeparator = "."
+	AssertEqual(t, "INF msg count=1.234.567 total=9.876.543 id=1234567\n", string(Render(rec, opts)))
+}
+
+func TestHandler_Units(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("latency", 42, "size", 2048, "ratio", 0.5, "name", "x", "pct", 12)
+	opts := &HandlerOptions{
+		NoColor:      true,
+		Units:        map[string]string{"latency": "ms", "size": "B", "ratio": "x", "name": "!"},
+		HumanizeKeys: []string{"size"},
+		FormatValue: func(key string, v slog.Value) (string, bool) {
+			return "12 percent", key == "pct"
+		},
+	}
+	AssertEqual(t, "INF msg latency=42ms size=2.0kB ratio=0.5x name=x pct=12 percent\n", string(Render(rec, opts)))
+}
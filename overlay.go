@@ -0,0 +1,46 @@
+package console
+
+import "io"
+
+// Overlay is implemented by renderers drawing in place on the terminal,
+// like progress bars or spinners. When an Overlay is attached to a handler,
+// it is cleared before each record is written, and drawn again after, so
+// that records and the overlay don't get mixed up on the terminal.
+//
+// The methods are called with the handler's output locked, and must not
+// log through the handler.
+type Overlay interface {
+	// Clear erases the overlay previously drawn to w, and leaves the
+	// cursor where the next record must be written.
+	Clear(w io.Writer)
+	// Draw draws the overlay to w.
+	Draw(w io.Writer)
+}
+
+// SetOverlay attaches o to the output of h, which is shared with all the
+// handlers derived from it, and draws it. The previous overlay, if any, is
+// cleared. If o is nil, the overlay is removed.
+func (h *Handler) SetOverlay(o Overlay) {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	if h.out.overlay != nil {
+		h.out.overlay.Clear(h.out.w)
+	}
+	h.out.overlay = o
+	if o != nil {
+		o.Draw(h.out.w)
+	}
+}
+
+// Redraw clears and draws again the overlay attached to h, if any.
+// Overlays should call it instead of writing to the terminal themselves
+// when their state changes, so that their output does not interleave
+// with records.
+func (h *Handler) Redraw() {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	if o := h.out.overlay; o != nil {
+		o.Clear(h.out.w)
+		o.Draw(h.out.w)
+	}
+}
@@ -0,0 +1,36 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type testOverlay struct{ text string }
+
+func (o *testOverlay) Clear(w io.Writer) { io.WriteString(w, "\r\x1b[K") }
+func (o *testOverlay) Draw(w io.Writer)  { io.WriteString(w, o.text) }
+
+func TestHandler_Overlay(t *testing.T) {
+	for _, opts := range []*HandlerOptions{
+		{NoColor: true},
+		{NoColor: true, Pool: PoolSingleGoroutine},
+	} {
+		buf := bytes.Buffer{}
+		h := NewHandler(&buf, opts)
+		o := &testOverlay{"[10%]"}
+		h.SetOverlay(o)
+		h2 := h.WithAttrs([]slog.Attr{slog.Int("a", 1)})
+		AssertNoError(t, h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)))
+		o.text = "[50%]"
+		h.Redraw()
+		h.SetOverlay(nil)
+		AssertNoError(t, h2.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "bye", 0)))
+		h.Redraw()
+
+		AssertEqual(t, "[10%]\r\x1b[KINF hello a=1\n[10%]\r\x1b[K[50%]\r\x1b[KINF bye a=1\n", buf.String())
+	}
+}
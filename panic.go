@@ -0,0 +1,73 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// PanicStackKey is the key of the attribute holding the stack trace
+// of a panic logged by LogPanics.
+const PanicStackKey = "stack"
+
+// LogPanics recovers a panic, logs it at error level with logger along with
+// the stack trace of the panicking goroutine, then panics again with the
+// same value. It must be deferred directly:
+//
+//	defer console.LogPanics(logger)
+func LogPanics(logger *slog.Logger) {
+	if r := recover(); r != nil {
+		logPanic(logger, r)
+		panic(r)
+	}
+}
+
+// LogPanicsAndExit is like LogPanics, but exits the program with code
+// instead of panicking again. It must be deferred directly:
+//
+//	defer console.LogPanicsAndExit(logger, 2)
+//
+// If logger's handler is a *Handler, its outputs are flushed and the
+// program exits with its HandlerOptions.ExitFunc, like for ExitLevel.
+func LogPanicsAndExit(logger *slog.Logger, code int) {
+	if r := recover(); r != nil {
+		logPanic(logger, r)
+		if h, ok := logger.Handler().(*Handler); ok {
+			h.exit(context.Background(), code)
+			return
+		}
+		os.Exit(code)
+	}
+}
+
+// logPanic logs the recovered panic r, with the function
+// which panicked as the source.
+func logPanic(logger *slog.Logger, r any) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, slog.LevelError) {
+		return
+	}
+	rec := slog.NewRecord(time.Now(), slog.LevelError, "panic: "+panicString(r), panicPC())
+	rec.AddAttrs(slog.String(PanicStackKey, string(debug.Stack())))
+	_ = logger.Handler().Handle(ctx, rec)
+}
+
+// panicPC returns the program counter of the function which panicked,
+// skipping the frames of the runtime: runtime.gopanic, and the functions
+// raising runtime errors, like runtime.panicIndex or runtime.sigpanic.
+func panicPC() uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(4, pcs[:]) // Skip runtime.Callers, panicPC, logPanic and its caller
+	for _, pc := range pcs[:n] {
+		// The source of a record is the first frame of its pc
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			return pc
+		}
+	}
+	return 0
+}
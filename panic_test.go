@@ -0,0 +1,59 @@
+package console
+
+import (
+	"bufio"
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogPanics(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, AddSource: true}))
+	var recovered any
+	func() {
+		defer func() { recovered = recover() }()
+		defer LogPanics(logger)
+		panicking()
+	}()
+	AssertEqual(t, "boom", recovered)
+	out := buf.String()
+	AssertEqual(t, true, strings.Contains(out, " ERR panic_test.go:"))
+	AssertEqual(t, true, strings.Contains(out, " > panic: boom stack=goroutine "))
+	AssertEqual(t, true, strings.Contains(out, "console-slog.panicking("))
+}
+
+func panicking() {
+	panic("boom")
+}
+
+func TestLogPanics_RuntimeError(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, AddSource: true}))
+	func() {
+		defer func() { _ = recover() }()
+		defer LogPanics(logger)
+		var m map[string]int
+		m["a"] = 1
+	}()
+	out := buf.String()
+	AssertEqual(t, true, strings.Contains(out, " ERR panic_test.go:"))
+	AssertEqual(t, true, strings.Contains(out, " > panic: assignment to entry in nil map "))
+}
+
+func TestLogPanicsAndExit(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	code := -1
+	logger := slog.New(NewHandler(w, &HandlerOptions{
+		NoColor:  true,
+		ExitFunc: func(c int) { code = c },
+	}))
+	func() {
+		defer LogPanicsAndExit(logger, 2)
+		panicking()
+	}()
+	AssertEqual(t, 2, code)
+	AssertEqual(t, true, strings.Contains(buf.String(), " ERR panic: boom stack=goroutine "))
+}
@@ -0,0 +1,166 @@
+package console
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseLine parses a single line produced by a Handler created with opts
+// back into its fields. It is meant for tests, to validate the output of
+// a given combination of options.
+//
+// The returned map uses slog.TimeKey, slog.LevelKey, slog.MessageKey and
+// slog.SourceKey for the built-in fields. Dotted attribute keys are
+// expanded into nested map[string]any, one per group. Attribute values
+// are returned as strings, the level as a slog.Level, and the timestamp
+// as a time.Time when it can be parsed with opts.TimeFormat, or as a
+// string otherwise.
+//
+// Since the console format does not quote values, parsing is best effort:
+// a space separated word without '=' is considered to be part of the
+// previous value, and with NestedGroups, closing braces ending a value are
+// taken as the end of the enclosing groups. Custom level encoders,
+// custom separators and BareTrueBools are not supported, nor are numeric
+// levels (LevelDeltaNumeric) combined with numeric timestamps.
+func ParseLine(line string, opts *HandlerOptions) (map[string]any, error) {
+	if opts == nil {
+		opts = new(HandlerOptions)
+	}
+	o := *opts
+	o.setDefaults()
+
+	line = strings.TrimSuffix(stripANSI(line), "\n")
+	words := strings.Split(line, " ")
+
+	// The level is the first word recognized as a level label.
+	// Everything before it is the timestamp.
+	lvlIdx := -1
+	var level slog.Level
+	names := byLevel(o.LevelNames)
+	numeric := o.LevelDelta == LevelDeltaNumeric
+	for i, w := range words {
+		if l, ok := parseLevel(w, names, numeric); ok {
+			lvlIdx, level = i, l
+			break
+		}
+	}
+	if lvlIdx < 0 {
+		return nil, errors.New("console: no level found in line")
+	}
+	m := map[string]any{slog.LevelKey: level}
+	if lvlIdx > 0 {
+		ts := strings.Join(words[:lvlIdx], " ")
+		if t, err := parseTime(ts, o.TimeFormat); err == nil && o.EncodeTimestamp == nil {
+			m[slog.TimeKey] = t
+		} else {
+			m[slog.TimeKey] = ts
+		}
+	}
+	words = words[lvlIdx+1:]
+	// Skip the padding of the level label
+	for len(words) > 0 && words[0] == "" {
+		words = words[1:]
+	}
+
+	if len(words) > 1 && words[1] == ">" {
+		m[slog.SourceKey] = words[0]
+		words = words[2:]
+	}
+
+	// The message spans until the first word looking like an attribute.
+	i := 0
+	for i < len(words) && !isAttrWord(words[i]) {
+		i++
+	}
+	m[slog.MessageKey] = strings.Join(words[:i], " ")
+
+	var key []string
+	var val []string
+	var stack []string // Enclosing nested groups
+	flush := func() {
+		if key != nil {
+			setNested(m, key, strings.Join(val, " "))
+			key = nil
+		}
+	}
+	for _, w := range words[i:] {
+		if !isAttrWord(w) {
+			val = append(val, w)
+			continue
+		}
+		flush()
+		// Open nested groups, like in "group={sub={k=v"
+		for {
+			k, v, _ := strings.Cut(w, "=")
+			if !strings.HasPrefix(v, "{") || !isAttrWord(v[1:]) {
+				break
+			}
+			stack = append(stack, k)
+			w = v[1:]
+		}
+		// Count the nested groups closed by this word
+		closed := 0
+		for closed < len(stack) && strings.HasSuffix(w, "}") {
+			w = w[:len(w)-1]
+			closed++
+		}
+		k, v, _ := strings.Cut(w, "=")
+		key = append(slices.Clone(stack), strings.Split(k, ".")...)
+		val = append(val[:0], v)
+		if closed > 0 {
+			flush()
+			stack = stack[:len(stack)-closed]
+		}
+	}
+	flush()
+	return m, nil
+}
+
+func isAttrWord(w string) bool {
+	i := strings.IndexByte(w, '=')
+	return i > 0
+}
+
+func setNested(m map[string]any, path []string, v string) {
+	for _, p := range path[:len(path)-1] {
+		sub, ok := m[p].(map[string]any)
+		if !ok {
+			sub = map[string]any{}
+			m[p] = sub
+		}
+		m = sub
+	}
+	m[path[len(path)-1]] = v
+}
+
+func parseLevel(s string, names map[slog.Level]string, numeric bool) (slog.Level, bool) {
+	for l, name := range names {
+		if s == name {
+			return l, true
+		}
+	}
+	if numeric {
+		n, err := strconv.Atoi(s)
+		return slog.Level(n), err == nil
+	}
+	return parseLevelLabel(s, levelLabels)
+}
+
+// levelLabels are the labels of the standard levels in the output.
+var levelLabels = map[string]slog.Level{
+	"DBG": slog.LevelDebug,
+	"INF": slog.LevelInfo,
+	"WRN": slog.LevelWarn,
+	"ERR": slog.LevelError,
+}
+
+// stripANSI removes the ANSI escape sequences from s.
+func stripANSI(s string) string {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return s
+	}
+	return string(appendStripANSI(nil, []byte(s)))
+}
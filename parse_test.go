@@ -0,0 +1,58 @@
+package console
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestHandler_SlogTest(t *testing.T) {
+	for _, opts := range []*HandlerOptions{
+		{NoColor: true},
+		{},
+		{AddSource: true, TimeFormat: time.RFC3339Nano},
+		{NestedGroups: true},
+	} {
+		buf := bytes.Buffer{}
+		h := NewHandler(&buf, opts)
+		results := func() []map[string]any {
+			var ms []map[string]any
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				m, err := ParseLine(line, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ms = append(ms, m)
+			}
+			return ms
+		}
+		AssertNoError(t, slogtest.TestHandler(h, results))
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	rec := slog.NewRecord(now, slog.LevelWarn+1, "hello world", 0)
+	rec.Add("err", "the error", slog.Group("g", "a", 1, slog.Group("h", "b", "c")))
+	m, err := ParseLine(string(Render(rec, nil)), nil)
+	AssertNoError(t, err)
+	AssertEqual(t, any(now), m[slog.TimeKey])
+	AssertEqual(t, any(slog.LevelWarn+1), m[slog.LevelKey])
+	AssertEqual(t, any("hello world"), m[slog.MessageKey])
+	AssertEqual(t, any("the error"), m["err"])
+	AssertEqual(t, any("1"), m["g"].(map[string]any)["a"])
+	AssertEqual(t, any("c"), m["g"].(map[string]any)["h"].(map[string]any)["b"])
+
+	opts := &HandlerOptions{NestedGroups: true}
+	m, err = ParseLine(string(Render(rec, opts)), opts)
+	AssertNoError(t, err)
+	AssertEqual(t, any("the error"), m["err"])
+	AssertEqual(t, any("1"), m["g"].(map[string]any)["a"])
+	AssertEqual(t, any("c"), m["g"].(map[string]any)["h"].(map[string]any)["b"])
+
+	_, err = ParseLine("not a log line", nil)
+	AssertError(t, err)
+}
@@ -0,0 +1,53 @@
+package console
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+// Percentage is a ratio, 1 meaning 100%, rendered as a percentage like
+// "93.5%". It can be styled after thresholds: when Warn and Error are not
+// both zero, values reaching Warn are styled with Theme.LevelWarn, and
+// values reaching Error with Theme.LevelError. If Warn is lower than Error,
+// high values are the bad ones, like for a CPU usage, otherwise low values
+// are, like for a cache hit rate.
+type Percentage struct {
+	Ratio float64
+	// Decimals is the number of decimals of the percentage.
+	Decimals    int
+	Warn, Error float64
+}
+
+func (p Percentage) String() string {
+	return string(p.append(nil))
+}
+
+func (p Percentage) append(dst []byte) []byte {
+	dst = strconv.AppendFloat(dst, p.Ratio*100, 'f', p.Decimals, 64)
+	return append(dst, '%')
+}
+
+// Percent returns an attribute holding ratio as a Percentage with 1 decimal:
+//
+//	logger.Info("cache", console.Percent("hit_rate", float64(hits)/float64(total)))
+func Percent(key string, ratio float64) slog.Attr {
+	return slog.Any(key, Percentage{Ratio: ratio, Decimals: 1})
+}
+
+// PercentThresholds is like Percent, with the value styled after
+// the warn and errAt thresholds. See Percentage.
+func PercentThresholds(key string, ratio, warn, errAt float64) slog.Attr {
+	return slog.Any(key, Percentage{Ratio: ratio, Decimals: 1, Warn: warn, Error: errAt})
+}
+
+// percentStyle returns the style of p, after its thresholds.
+func (e *encoder) percentStyle(p Percentage) ANSIMod {
+	switch {
+	case p.Warn == 0 && p.Error == 0:
+	case p.Warn <= p.Error && p.Ratio >= p.Error, p.Warn > p.Error && p.Ratio <= p.Error:
+		return e.opts.Theme.LevelError()
+	case p.Warn <= p.Error && p.Ratio >= p.Warn, p.Warn > p.Error && p.Ratio <= p.Warn:
+		return e.opts.Theme.LevelWarn()
+	}
+	return e.opts.Theme.AttrValue()
+}
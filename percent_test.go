@@ -0,0 +1,42 @@
+package console
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestPercentage(t *testing.T) {
+	AssertEqual(t, "93.5%", Percentage{Ratio: 0.935, Decimals: 1}.String())
+	AssertEqual(t, "94%", Percentage{Ratio: 0.935}.String())
+	AssertEqual(t, "12.346%", Percentage{Ratio: 0.1234567, Decimals: 3}.String())
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.AddAttrs(Percent("cpu", 0.5))
+	AssertEqual(t, "INF msg cpu=50.0%\n", string(Render(rec, &HandlerOptions{NoColor: true})))
+}
+
+func TestPercentStyle(t *testing.T) {
+	theme := NewDefaultTheme()
+	e := newEncoder(HandlerOptions{Theme: theme}, nil)
+	for _, tc := range []struct {
+		p        Percentage
+		expected ANSIMod
+	}{
+		{Percentage{Ratio: 0.99}, theme.AttrValue()},
+		// High is bad
+		{Percentage{Ratio: 0.5, Warn: 0.8, Error: 0.95}, theme.AttrValue()},
+		{Percentage{Ratio: 0.8, Warn: 0.8, Error: 0.95}, theme.LevelWarn()},
+		{Percentage{Ratio: 0.99, Warn: 0.8, Error: 0.95}, theme.LevelError()},
+		// Low is bad
+		{Percentage{Ratio: 0.99, Warn: 0.9, Error: 0.5}, theme.AttrValue()},
+		{Percentage{Ratio: 0.7, Warn: 0.9, Error: 0.5}, theme.LevelWarn()},
+		{Percentage{Ratio: 0.1, Warn: 0.9, Error: 0.5}, theme.LevelError()},
+	} {
+		AssertEqual(t, tc.expected, e.percentStyle(tc.p))
+	}
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.AddAttrs(PercentThresholds("cpu", 0.99, 0.8, 0.95))
+	out := string(Render(rec, &HandlerOptions{Theme: theme}))
+	AssertEqual(t, string(theme.LevelError())+"99.0%"+string(ResetMod)+"\n", out[len(out)-len(theme.LevelError())-len("99.0%")-len(ResetMod)-1:])
+}
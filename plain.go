@@ -0,0 +1,65 @@
+package console
+
+import "strings"
+
+// applyPlain resets the options decorating the output when Plain is set.
+// The other options, like the level and the destinations, are kept.
+func (o *HandlerOptions) applyPlain() {
+	if !o.Plain {
+		return
+	}
+	o.NoColor = true
+	o.AddSource = false
+	o.SourceAsAttr = false
+	o.Verbose = false
+	o.CIMarkers = CIMarkersNone
+	o.ColorProfile = ProfileTrueColor
+	o.ColorFromEnv = false
+	o.NoValueColor = false
+	o.FloatFormat = FloatAuto
+	o.HumanizeNumbers = false
+	o.HumanizeKeys = nil
+	o.GroupDigitsKeys = nil
+	o.DigitSeparator = ""
+	o.Units = nil
+	o.RelativeTimes = false
+	o.Theme = nil
+	o.KeyStyles = nil
+	o.GroupThemes = nil
+	o.EncodeTimestamp = nil
+	o.LevelNames = nil
+	o.LevelStyles = nil
+	o.LevelDelta = LevelDeltaShow
+	o.LevelWidth = 0
+	o.EncodeLevel = nil
+	o.EncodeSource = nil
+	o.EncodeKey = nil
+	o.EncodeFallback = nil
+	o.FormatValue = nil
+	o.HideAttrs = false
+	o.HideTime = false
+	o.HideLevel = false
+	o.KeyValueSeparator = ""
+	o.AttrSeparator = ""
+	o.BareTrueBools = false
+	o.NestedGroups = false
+	o.HideGroupPrefix = false
+	o.IndentGroups = false
+	o.CorrelationKey = ""
+	o.Indent = 0
+	o.AdaptiveHeaders = false
+	o.AlignValues = false
+	o.HighlightChanges = false
+}
+
+// lineBreaks escapes the line breaks with escapeNewlines.
+var lineBreaks = strings.NewReplacer("\n", `\n`, "\r", `\r`)
+
+// escapeNewlines replaces the line breaks of s with "\n" and "\r", so
+// that records written in plain mode always fit on a single line.
+func escapeNewlines(s string) string {
+	if strings.IndexAny(s, "\r\n") < 0 {
+		return s
+	}
+	return lineBreaks.Replace(s)
+}
@@ -0,0 +1,65 @@
+package console
+
+// PoolMode selects how a handler reuses the buffers records are rendered into.
+type PoolMode int
+
+const (
+	// PoolShared reuses buffers from a pool shared by all the handlers
+	// of the package. This is the default.
+	PoolShared PoolMode = iota
+	// PoolLocal reuses buffers from a pool owned by the handler, and
+	// shared with the handlers derived from it. This isolates the memory
+	// used by a handler from the others.
+	PoolLocal
+	// PoolNone allocates a new buffer for every record.
+	PoolNone
+	// PoolSingleGoroutine reuses a single buffer, shared by the handler
+	// and the handlers derived from it, and writes records to the output
+	// without any locking. This gives the best throughput for single
+	// goroutine programs like CLIs, but such handlers must not be used
+	// concurrently, and SetOutput and SetOverlay must not be called while logging.
+	PoolSingleGoroutine
+)
+
+// bufferPool provides the buffers records are rendered into.
+type bufferPool interface {
+	get() *buffer
+	put(*buffer)
+}
+
+func newBufferPool(mode PoolMode) bufferPool {
+	switch mode {
+	case PoolLocal:
+		return newSyncPool()
+	case PoolNone:
+		return noPool{}
+	case PoolSingleGoroutine:
+		return new(singleBuffer)
+	default:
+		return sharedPool
+	}
+}
+
+// noPool allocates a new buffer every time.
+type noPool struct{}
+
+func (noPool) get() *buffer { return new(buffer) }
+func (noPool) put(*buffer)  {}
+
+// singleBuffer holds at most one buffer, without synchronization.
+type singleBuffer struct {
+	b *buffer
+}
+
+func (p *singleBuffer) get() *buffer {
+	b := p.b
+	if b == nil {
+		return new(buffer)
+	}
+	p.b = nil
+	return b
+}
+
+func (p *singleBuffer) put(b *buffer) {
+	p.b = b
+}
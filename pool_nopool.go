@@ -0,0 +1,14 @@
+//go:build tinygo || js || console_nopool
+
+package console
+
+// On TinyGo and js/wasm, sync.Pool is either a no-op or adds overhead
+// for little benefit, since the program is mostly single threaded.
+// A new buffer is allocated for each record instead, whatever the PoolMode.
+// This implementation can be forced with the console_nopool build tag.
+
+var sharedPool bufferPool = noPool{}
+
+func newSyncPool() bufferPool {
+	return noPool{}
+}
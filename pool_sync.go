@@ -0,0 +1,25 @@
+//go:build !tinygo && !js && !console_nopool
+
+package console
+
+import "sync"
+
+var sharedPool = newSyncPool()
+
+type syncPool struct {
+	p sync.Pool
+}
+
+func newSyncPool() bufferPool {
+	return &syncPool{p: sync.Pool{
+		New: func() any { return new(buffer) },
+	}}
+}
+
+func (p *syncPool) get() *buffer {
+	return p.p.Get().(*buffer)
+}
+
+func (p *syncPool) put(b *buffer) {
+	p.p.Put(b)
+}
@@ -0,0 +1,52 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// PreviewTheme writes to w a sample of records rendered with theme,
+// covering all the levels, errors, groups and multiline values, under a
+// divider titled with the name of the theme. It allows theme authors to
+// review their changes, and applications to preview the available themes:
+//
+//	for _, t := range []console.Theme{console.NewDefaultTheme(), console.NewNordTheme()} {
+//		console.PreviewTheme(os.Stdout, t)
+//	}
+func PreviewTheme(w io.Writer, theme Theme) error {
+	h := NewHandler(w, &HandlerOptions{
+		Theme:      theme,
+		Level:      slog.LevelDebug,
+		TimeFormat: time.TimeOnly,
+		AddSource:  true,
+	})
+	if err := h.Divider(theme.Name()); err != nil {
+		return err
+	}
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	now := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.Local)
+	record := func(level slog.Level, msg string, attrs ...slog.Attr) slog.Record {
+		rec := slog.NewRecord(now, level, msg, pcs[0])
+		rec.AddAttrs(attrs...)
+		now = now.Add(1250 * time.Millisecond)
+		return rec
+	}
+	for _, rec := range []slog.Record{
+		record(slog.LevelDebug, "cache lookup", slog.String("key", "user:42"), slog.Bool("hit", false)),
+		record(slog.LevelInfo, "server started", slog.String("addr", ":8080"), slog.Any("elapsed", Elapsed(120*time.Millisecond))),
+		record(slog.LevelWarn, "slow request", slog.Group("http", slog.String("method", "GET"), slog.String("path", "/users")),
+			Bytes("size", 1536), slog.Any("latency", Elapsed(2300*time.Millisecond))),
+		record(slog.LevelError, "request failed", slog.Any("err", errors.New("connection refused")), slog.Int("retries", 3)),
+		record(slog.LevelInfo, "config reloaded", slog.String("diff", "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-workers: 4\n+workers: 8\n timeout: 30s")),
+	} {
+		if err := h.Handle(context.Background(), rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
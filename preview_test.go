@@ -0,0 +1,25 @@
+package console
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreviewTheme(t *testing.T) {
+	buf := bytes.Buffer{}
+	AssertNoError(t, PreviewTheme(&buf, NewNordTheme()))
+	out := stripANSI(buf.String())
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	AssertEqual(t, 11, len(lines))
+	AssertEqual(t, true, strings.Contains(lines[0], " Nord "))
+	for i, level := range []string{"DBG", "INF", "WRN", "ERR", "INF"} {
+		AssertEqual(t, true, strings.Contains(lines[i+1], " "+level+" preview.go:"))
+	}
+	AssertEqual(t, true, strings.Contains(out, "http.method=GET"))
+	AssertEqual(t, true, strings.Contains(out, "err=connection refused"))
+	AssertEqual(t, true, strings.Contains(buf.String(), string(diffAddedStyle(NewNordTheme()))+"+workers: 8"))
+
+	AssertError(t, PreviewTheme(writerFunc(func(b []byte) (int, error) { return 0, errors.New("nope") }), NewNordTheme()))
+}
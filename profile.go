@@ -0,0 +1,247 @@
+package console
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ColorProfile is a level of color support of a terminal. Setting
+// HandlerOptions.ColorProfile converts the colors of the themes to the
+// nearest ones supported, so that a single true color theme works
+// everywhere.
+type ColorProfile int
+
+const (
+	// ProfileTrueColor supports 24-bit colors. Colors are kept as is.
+	ProfileTrueColor ColorProfile = iota
+	// Profile256 supports the xterm 256 colors palette.
+	Profile256
+	// Profile16 supports the 8 basic colors and their bright variants.
+	Profile16
+	// ProfileNone doesn't support colors. It implies NoColor.
+	ProfileNone
+)
+
+// DetectColorProfile returns the color profile of the terminal, from the
+// COLORTERM and TERM environment variables:
+//
+//	opts.ColorProfile = console.DetectColorProfile()
+func DetectColorProfile() ColorProfile {
+	switch ct := os.Getenv("COLORTERM"); ct {
+	case "truecolor", "24bit":
+		return ProfileTrueColor
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "dumb":
+		return ProfileNone
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.Contains(term, "direct"):
+		return ProfileTrueColor
+	case strings.Contains(term, "256color"):
+		return Profile256
+	default:
+		return Profile16
+	}
+}
+
+// applyProfile converts the colors of the themes to the color profile.
+func (o *HandlerOptions) applyProfile() {
+	switch o.ColorProfile {
+	case ProfileTrueColor:
+		return
+	case ProfileNone:
+		o.NoColor = true
+		return
+	}
+	o.Theme = convertTheme(o.Theme, o.ColorProfile)
+	if o.GroupThemes != nil {
+		themes := make(map[string]Theme, len(o.GroupThemes))
+		for name, t := range o.GroupThemes {
+			themes[name] = convertTheme(t, o.ColorProfile)
+		}
+		o.GroupThemes = themes
+	}
+	if o.KeyStyles != nil {
+		styles := make(map[string]ANSIMod, len(o.KeyStyles))
+		for key, m := range o.KeyStyles {
+			styles[key] = convertMod(m, o.ColorProfile)
+		}
+		o.KeyStyles = styles
+	}
+	if o.LevelStyles != nil {
+		styles := make(map[slog.Leveler]ANSIMod, len(o.LevelStyles))
+		for l, m := range o.LevelStyles {
+			styles[l] = convertMod(m, o.ColorProfile)
+		}
+		o.LevelStyles = styles
+	}
+}
+
+// convertTheme returns t with its colors converted to the color profile p.
+func convertTheme(t Theme, p ColorProfile) Theme {
+	if pt, ok := t.(*profileTheme); ok {
+		t = pt.theme
+	}
+	return &profileTheme{theme: t, profile: p, cache: make(map[ANSIMod]ANSIMod)}
+}
+
+// profileTheme converts the styles of a theme to a color profile when they
+// are used, so that the methods of the theme, like a custom Level, apply.
+// The converted styles are cached.
+type profileTheme struct {
+	theme   Theme
+	profile ColorProfile
+	mu      sync.RWMutex
+	cache   map[ANSIMod]ANSIMod
+}
+
+func (t *profileTheme) convert(m ANSIMod) ANSIMod {
+	if m == "" {
+		return m
+	}
+	t.mu.RLock()
+	c, ok := t.cache[m]
+	t.mu.RUnlock()
+	if ok {
+		return c
+	}
+	c = convertMod(m, t.profile)
+	t.mu.Lock()
+	if len(t.cache) >= maxKeySets {
+		clear(t.cache)
+	}
+	t.cache[m] = c
+	t.mu.Unlock()
+	return c
+}
+
+func (t *profileTheme) Name() string                { return t.theme.Name() }
+func (t *profileTheme) Timestamp() ANSIMod          { return t.convert(t.theme.Timestamp()) }
+func (t *profileTheme) Source() ANSIMod             { return t.convert(t.theme.Source()) }
+func (t *profileTheme) Message() ANSIMod            { return t.convert(t.theme.Message()) }
+func (t *profileTheme) MessageDebug() ANSIMod       { return t.convert(t.theme.MessageDebug()) }
+func (t *profileTheme) AttrKey() ANSIMod            { return t.convert(t.theme.AttrKey()) }
+func (t *profileTheme) AttrValue() ANSIMod          { return t.convert(t.theme.AttrValue()) }
+func (t *profileTheme) AttrValueError() ANSIMod     { return t.convert(t.theme.AttrValueError()) }
+func (t *profileTheme) LevelError() ANSIMod         { return t.convert(t.theme.LevelError()) }
+func (t *profileTheme) LevelWarn() ANSIMod          { return t.convert(t.theme.LevelWarn()) }
+func (t *profileTheme) LevelInfo() ANSIMod          { return t.convert(t.theme.LevelInfo()) }
+func (t *profileTheme) LevelDebug() ANSIMod         { return t.convert(t.theme.LevelDebug()) }
+func (t *profileTheme) Level(l slog.Level) ANSIMod  { return t.convert(t.theme.Level(l)) }
+func (t *profileTheme) AttrGroup() ANSIMod          { return t.convert(attrGroupStyle(t.theme)) }
+func (t *profileTheme) SourceSeparator() ANSIMod    { return t.convert(sourceSeparatorStyle(t.theme)) }
+func (t *profileTheme) Punctuation() ANSIMod        { return t.convert(punctuationStyle(t.theme)) }
+func (t *profileTheme) DiffAdded() ANSIMod          { return t.convert(diffAddedStyle(t.theme)) }
+func (t *profileTheme) DiffRemoved() ANSIMod        { return t.convert(diffRemovedStyle(t.theme)) }
+func (t *profileTheme) AttrValueChanged() ANSIMod   { return t.convert(changedStyle(t.theme, true)) }
+func (t *profileTheme) AttrValueUnchanged() ANSIMod { return t.convert(changedStyle(t.theme, false)) }
+
+// convertMod converts the 24-bit and 256 colors of the mode m, which may
+// be made of several sequences, to the nearest colors of the profile p.
+func convertMod(m ANSIMod, p ColorProfile) ANSIMod {
+	s := string(m)
+	if !strings.Contains(s, "38;") && !strings.Contains(s, "48;") {
+		return m
+	}
+	var modes []int
+	for _, seq := range strings.Split(s, "\x1b[") {
+		if seq == "" {
+			continue
+		}
+		var ps []int
+		for _, f := range strings.Split(strings.TrimSuffix(seq, "m"), ";") {
+			n, _ := strconv.Atoi(f)
+			ps = append(ps, n)
+		}
+		for i := 0; i < len(ps); i++ {
+			n := ps[i]
+			if (n != 38 && n != 48) || i+1 >= len(ps) {
+				modes = append(modes, n)
+				continue
+			}
+			var idx uint8 // Color index in the 256 colors palette
+			switch {
+			case ps[i+1] == 2 && i+4 < len(ps):
+				r, g, b := uint8(ps[i+2]), uint8(ps[i+3]), uint8(ps[i+4])
+				i += 4
+				if p == Profile16 {
+					modes = append(modes, basicColorMode(nearestBasic(r, g, b), n == 48))
+					continue
+				}
+				idx = nearest256(r, g, b)
+			case ps[i+1] == 5 && i+2 < len(ps):
+				idx = uint8(ps[i+2])
+				i += 2
+			default:
+				modes = append(modes, n)
+				continue
+			}
+			if p == Profile16 {
+				if idx >= 16 {
+					idx = nearestBasic(xterm256RGB(idx))
+				}
+				modes = append(modes, basicColorMode(idx, n == 48))
+				continue
+			}
+			modes = append(modes, n, 5, int(idx))
+		}
+	}
+	return ToANSICode(modes...)
+}
+
+// basicColorMode returns the mode of the basic color n, from 0 to 15,
+// for the foreground or the background.
+func basicColorMode(n uint8, background bool) int {
+	mode := int(n) + Black
+	if n >= 8 {
+		mode = int(n-8) + BrightBlack
+	}
+	if background {
+		mode += 10
+	}
+	return mode
+}
+
+// nearestBasic returns the index of the basic color nearest to r, g, b.
+func nearestBasic(r, g, b uint8) uint8 {
+	best, bestDist := uint8(0), -1
+	for i := uint8(0); i < 16; i++ {
+		cr, cg, cb := xterm256RGB(i)
+		if d := colorDist(r, g, b, cr, cg, cb); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// nearest256 returns the index of the color of the xterm 256 colors
+// cube or grayscale ramp nearest to r, g, b. The basic colors are
+// excluded, as terminals customize them.
+func nearest256(r, g, b uint8) uint8 {
+	level := func(c uint8) uint8 {
+		if c < 48 {
+			return 0
+		}
+		if c < 115 {
+			return 1
+		}
+		return (c - 35) / 40
+	}
+	cube := 16 + 36*level(r) + 6*level(g) + level(b)
+	avg := (int(r) + int(g) + int(b)) / 3
+	gray := uint8(232 + min(max((avg-3)/10, 0), 23))
+	cr, cg, cb := xterm256RGB(cube)
+	gr, gg, gb := xterm256RGB(gray)
+	if colorDist(r, g, b, gr, gg, gb) < colorDist(r, g, b, cr, cg, cb) {
+		return gray
+	}
+	return cube
+}
+
+func colorDist(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,82 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestDetectColorProfile(t *testing.T) {
+	for _, tc := range []struct {
+		colorTerm, term string
+		expected        ColorProfile
+	}{
+		{"truecolor", "xterm", ProfileTrueColor},
+		{"", "xterm-direct", ProfileTrueColor},
+		{"", "xterm-256color", Profile256},
+		{"", "xterm", Profile16},
+		{"", "", Profile16},
+		{"", "dumb", ProfileNone},
+	} {
+		t.Setenv("COLORTERM", tc.colorTerm)
+		t.Setenv("TERM", tc.term)
+		AssertEqual(t, tc.expected, DetectColorProfile())
+	}
+}
+
+func TestConvertMod(t *testing.T) {
+	for _, tc := range []struct {
+		mod      ANSIMod
+		profile  ColorProfile
+		expected ANSIMod
+	}{
+		{ToANSICode(Bold) + Hex("#ff8800"), Profile256, ToANSICode(Bold, 38, 5, 208)},
+		{Hex("#ff8800"), Profile16, ToANSICode(BrightRed)},
+		{Hex("#808080"), Profile256, Color256(244)},
+		{Color256(196), Profile16, ToANSICode(Red)},
+		{Color256(2), Profile16, ToANSICode(Green)},
+		{ToANSICode(48, 2, 0, 0, 0), Profile16, ToANSICode(40)},
+		{ToANSICode(Bold, Red), Profile16, ToANSICode(Bold, Red)},
+		{Color256(208), Profile256, Color256(208)},
+		{"", Profile16, ""},
+	} {
+		AssertEqual(t, tc.expected, convertMod(tc.mod, tc.profile))
+	}
+}
+
+func TestHandler_ColorProfile(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	AssertEqual(t, "\x1b[38;5;114mINF\x1b[0m \x1b[1mmsg\x1b[0m\n",
+		string(Render(rec, &HandlerOptions{Theme: NewTrueColorTheme(), ColorProfile: Profile256})))
+
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{Theme: NewTrueColorTheme(), ColorProfile: ProfileNone})
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF msg\n", buf.String())
+}
+
+type traceTheme struct {
+	Theme
+}
+
+func (t traceTheme) Level(l slog.Level) ANSIMod {
+	if l < slog.LevelDebug {
+		return Hex("#ff8800")
+	}
+	return t.Theme.Level(l)
+}
+
+func TestHandler_ColorProfileMethods(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelDebug-4, "msg", 0)
+	opts := &HandlerOptions{Theme: traceTheme{NewTrueColorTheme()}, ColorProfile: Profile16, Level: slog.LevelDebug - 4}
+	level := string(ToANSICode(BrightRed)) + "DBG-4" + string(ResetMod)
+	AssertEqual(t, level, string(Render(rec, opts))[:len(level)])
+
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, opts)
+	h.SetNoColor(false)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, string(Render(rec, opts)), buf.String())
+}
@@ -0,0 +1,154 @@
+package console
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactMode is the strategy used to mask redacted values.
+type RedactMode int
+
+const (
+	// RedactFull replaces the whole value with RedactedValue.
+	RedactFull RedactMode = iota
+	// RedactPartial only keeps the last 4 characters of values longer
+	// than 8 characters, like "****cdef", and masks shorter ones fully.
+	RedactPartial
+	// RedactHash replaces the value with the beginning of its SHA-256
+	// hash, like "sha256:2bb80d53", so that equal secrets can be
+	// correlated without being revealed.
+	RedactHash
+)
+
+// RedactedValue replaces the values fully masked by a Redactor.
+const RedactedValue = "******"
+
+// Redactor masks the values of the attributes whose key designates a
+// secret. Keys are compared to the attribute keys, not including the
+// enclosing groups, ignoring case.
+type Redactor struct {
+	// Keys are the attribute keys to redact, like "password" or "token".
+	Keys []string
+	// Patterns are regular expressions matched against the attribute keys.
+	Patterns []*regexp.Regexp
+	// Mode is the masking strategy.
+	Mode RedactMode
+
+	// DetectSecrets enables the scanning of the values rendered as text,
+	// that is strings, errors and fmt.Stringer, whatever their key, for
+	// likely secrets: JSON web tokens, AWS access keys, bearer
+	// tokens and long hexadecimal or base64 blobs. The secrets found are
+	// masked in place, the rest of the value being kept.
+	DetectSecrets bool
+	// OnSecret, if set, is called with the attribute key and the kind of
+	// secret, like "jwt", every time DetectSecrets masks a secret.
+	OnSecret func(key, kind string)
+}
+
+// secretDetectors recognize likely secrets, in order of precedence.
+var secretDetectors = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{"jwt", regexp.MustCompile(`eyJ[\w-]+\.eyJ[\w-]+\.[\w-]+`)},
+	{"bearer", regexp.MustCompile(`(?i)\bbearer\s+[\w.~+/-]+=*`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"hex", regexp.MustCompile(`\b[0-9a-fA-F]{32,}\b`)},
+	{"base64", regexp.MustCompile(`[A-Za-z0-9+/_-]{40,}={0,2}`)},
+}
+
+// scan masks the likely secrets found in the value s of the attribute key.
+// It reports whether any was found.
+func (r *Redactor) scan(key, s string) (string, bool) {
+	found := false
+	for _, d := range secretDetectors {
+		s = d.re.ReplaceAllStringFunc(s, func(m string) string {
+			found = true
+			if r.OnSecret != nil {
+				r.OnSecret(key, d.kind)
+			}
+			return r.Mask(slog.StringValue(m))
+		})
+	}
+	return s, found
+}
+
+// textValue returns the text of the values rendered as text: strings,
+// errors and fmt.Stringer. It reports false for other values, or if their
+// method panics, leaving the panic to be reported when writing the value.
+func textValue(v slog.Value) (s string, ok bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String(), true
+	case slog.KindAny:
+		defer func() {
+			if recover() != nil {
+				s, ok = "", false
+			}
+		}()
+		switch v := v.Any().(type) {
+		case error:
+			return v.Error(), true
+		case fmt.Stringer:
+			return v.String(), true
+		}
+	}
+	return "", false
+}
+
+// NewRedactor creates a Redactor masking the values of the given keys
+// with mode. If no keys are given, DefaultRedactedKeys are used.
+func NewRedactor(mode RedactMode, keys ...string) *Redactor {
+	if len(keys) == 0 {
+		keys = DefaultRedactedKeys
+	}
+	return &Redactor{Keys: keys, Mode: mode}
+}
+
+// DefaultRedactedKeys are the keys redacted by NewRedactor when none are given.
+var DefaultRedactedKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie"}
+
+// Matches reports whether the value of the attribute with the given key
+// must be redacted.
+func (r *Redactor) Matches(key string) bool {
+	for _, k := range r.Keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, p := range r.Patterns {
+		if p.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask returns the masked form of v.
+func (r *Redactor) Mask(v slog.Value) string {
+	if v.Kind() == slog.KindGroup {
+		return RedactedValue
+	}
+	s := v.String()
+	switch r.Mode {
+	case RedactPartial:
+		if utf8.RuneCountInString(s) > 8 {
+			// Keep the last 4 runes, not bytes, not to split a character
+			i := len(s)
+			for n := 0; n < 4; n++ {
+				_, size := utf8.DecodeLastRuneInString(s[:i])
+				i -= size
+			}
+			return "****" + s[i:]
+		}
+	case RedactHash:
+		sum := sha256.Sum256([]byte(s))
+		return "sha256:" + hex.EncodeToString(sum[:4])
+	}
+	return RedactedValue
+}
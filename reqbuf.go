@@ -0,0 +1,149 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Gutters drawn before the records of a block flushed by FlushRecords.
+const (
+	blockStart = "┌ "
+	blockCont  = "│ "
+	blockEnd   = "└ "
+)
+
+// MaxBufferedRecords is the number of records a context returned by
+// BufferRecords holds at most. The buffer is flushed when it is full.
+const MaxBufferedRecords = 1000
+
+type recordBufferKey struct{}
+
+// recordBuffer holds the records rendered under a context
+// returned by BufferRecords.
+type recordBuffer struct {
+	mu      sync.Mutex
+	records []bufferedRecord
+}
+
+type bufferedRecord struct {
+	out   *output
+	line  []byte
+	plain bool     // Written without gutter, for HandlerOptions.Plain
+	stats *Metrics // Of the handler, counting the record once written
+}
+
+// BufferRecords returns a copy of ctx under which the records handled by
+// a *Handler, like the ones of a request, are kept in memory instead of
+// being written, until FlushRecords is called. They are then written as
+// a single contiguous block, so that the records of concurrent requests
+// do not interleave. Records at error level or above flush the buffer
+// immediately, as does reaching MaxBufferedRecords records, so that the
+// memory of a long running task stays bounded.
+func BufferRecords(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recordBufferKey{}, new(recordBuffer))
+}
+
+// FlushRecords writes the records buffered under ctx, as one block with
+// a gutter grouping them, and empties the buffer. It does nothing if ctx
+// doesn't come from BufferRecords.
+func FlushRecords(ctx context.Context) error {
+	if b := recordBufferFrom(ctx); b != nil {
+		return b.flush()
+	}
+	return nil
+}
+
+func recordBufferFrom(ctx context.Context) *recordBuffer {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(recordBufferKey{}).(*recordBuffer)
+	return b
+}
+
+// add buffers the rendered record line, to be written to out, without
+// gutter if plain is true, and counted with stats. It flushes the buffer
+// if the record is at error level or above, or if the buffer is full.
+func (b *recordBuffer) add(out *output, line []byte, l slog.Level, plain bool, stats *Metrics) error {
+	b.mu.Lock()
+	b.records = append(b.records, bufferedRecord{out, append([]byte(nil), line...), plain, stats})
+	full := len(b.records) >= MaxBufferedRecords
+	b.mu.Unlock()
+	if l >= slog.LevelError || full {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *recordBuffer) flush() error {
+	b.mu.Lock()
+	records := b.records
+	b.records = nil
+	b.mu.Unlock()
+
+	// Records may have been handled by handlers with different outputs.
+	// Each output gets a single write with its own records.
+	var err error
+	for len(records) > 0 {
+		out := records[0].out
+		var mine, others []bufferedRecord
+		for _, r := range records {
+			if r.out == out {
+				mine = append(mine, r)
+			} else {
+				others = append(others, r)
+			}
+		}
+		_, e := out.Write(appendBlock(nil, mine))
+		for _, r := range mine {
+			r.stats.recordWritten(int64(len(r.line)), e)
+		}
+		if e != nil && err == nil {
+			err = e
+		}
+		records = others
+	}
+	return err
+}
+
+// appendBlock appends the lines of records, preceded by the gutter of
+// the block, unless there is a single record or the record is plain. The
+// gutter runs along every line, including the continuation lines of the
+// records spanning several lines.
+func appendBlock(dst []byte, records []bufferedRecord) []byte {
+	if len(records) == 1 {
+		return append(dst, records[0].line...)
+	}
+	lines := 0
+	for _, r := range records {
+		if !r.plain {
+			lines += bytes.Count(r.line, []byte{'\n'})
+		}
+	}
+	i := 0
+	for _, r := range records {
+		if r.plain {
+			dst = append(dst, r.line...)
+			continue
+		}
+		for line := r.line; len(line) > 0; i++ {
+			switch {
+			case i == 0:
+				dst = append(dst, blockStart...)
+			case i == lines-1:
+				dst = append(dst, blockEnd...)
+			default:
+				dst = append(dst, blockCont...)
+			}
+			n := bytes.IndexByte(line, '\n') + 1
+			if n == 0 {
+				n = len(line)
+			}
+			dst = append(dst, line[:n]...)
+			line = line[n:]
+		}
+	}
+	return dst
+}
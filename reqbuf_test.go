@@ -0,0 +1,53 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestBufferRecords(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true}))
+	ctx := BufferRecords(context.Background())
+	logger.InfoContext(ctx, "start")
+	logger.InfoContext(context.Background(), "other")
+	logger.With("a", 1).InfoContext(ctx, "step")
+	AssertEqual(t, "INF other\n", buf.String())
+	logger.InfoContext(ctx, "done")
+	AssertNoError(t, FlushRecords(ctx))
+	AssertEqual(t, "INF other\n┌ INF start\n│ INF step a=1\n└ INF done\n", buf.String())
+
+	// Errors flush immediately
+	buf.Reset()
+	logger.InfoContext(ctx, "start")
+	logger.ErrorContext(ctx, "failed")
+	AssertEqual(t, "┌ INF start\n└ ERR failed\n", buf.String())
+	logger.InfoContext(ctx, "alone")
+	AssertNoError(t, FlushRecords(ctx))
+	AssertEqual(t, "┌ INF start\n└ ERR failed\nINF alone\n", buf.String())
+
+	AssertNoError(t, FlushRecords(ctx))
+	AssertNoError(t, FlushRecords(context.Background()))
+
+	// Continuation lines
+	buf.Reset()
+	logger.InfoContext(ctx, "start")
+	logger.InfoContext(ctx, "multi\nline")
+	AssertNoError(t, FlushRecords(ctx))
+	AssertEqual(t, "┌ INF start\n│ INF multi\n└ line\n", buf.String())
+}
+
+func TestBufferRecords_Full(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true}))
+	ctx := BufferRecords(context.Background())
+	for i := 0; i < MaxBufferedRecords-1; i++ {
+		logger.InfoContext(ctx, "x")
+	}
+	AssertEqual(t, 0, buf.Len())
+	logger.InfoContext(ctx, "last")
+	AssertEqual(t, MaxBufferedRecords, bytes.Count(buf.Bytes(), []byte{'\n'}))
+	AssertEqual(t, 0, len(recordBufferFrom(ctx).records))
+}
@@ -0,0 +1,28 @@
+package console
+
+import (
+	"log/slog"
+	"runtime"
+)
+
+// RuntimeKey is the key of the group returned by RuntimeStats.
+const RuntimeKey = "runtime"
+
+// RuntimeStats returns a group holding the current memory and goroutine
+// statistics of the program: the heap memory in use, the number of
+// completed GC cycles and the number of goroutines. It is meant for
+// periodic health records of long-running programs:
+//
+//	logger.Info("health", console.RuntimeStats())
+//
+// It calls runtime.ReadMemStats, which stops the world, so it should
+// not be called too often.
+func RuntimeStats() slog.Attr {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return slog.Group(RuntimeKey,
+		slog.Any("heap_inuse", ByteSize(m.HeapInuse)),
+		slog.Uint64("gc", uint64(m.NumGC)),
+		slog.Int("goroutines", runtime.NumGoroutine()),
+	)
+}
@@ -0,0 +1,21 @@
+package console
+
+import (
+	"testing"
+)
+
+func TestRuntimeStats(t *testing.T) {
+	a := RuntimeStats()
+	AssertEqual(t, RuntimeKey, a.Key)
+	keys := []string{}
+	for _, sub := range a.Value.Group() {
+		keys = append(keys, sub.Key)
+	}
+	AssertEqual(t, 3, len(keys))
+	AssertEqual(t, "heap_inuse", keys[0])
+	AssertEqual(t, "gc", keys[1])
+	AssertEqual(t, "goroutines", keys[2])
+	AssertEqual(t, true, a.Value.Group()[2].Value.Int64() > 0)
+	_, ok := a.Value.Group()[0].Value.Any().(ByteSize)
+	AssertEqual(t, true, ok)
+}
@@ -0,0 +1,45 @@
+package console
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// source is a resolved source code position.
+type source struct {
+	frame runtime.Frame
+	// file is the path of frame.File, relative to the working directory
+	// when possible.
+	file string
+}
+
+// sourceCache holds the source code positions resolved so far. Resolving
+// a program counter allocates, while the number of logging call sites in a
+// program is bounded, so they are kept forever.
+type sourceCache struct {
+	mu sync.RWMutex
+	m  map[uintptr]source
+}
+
+var sources = sourceCache{m: make(map[uintptr]source)}
+
+func (c *sourceCache) get(pc uintptr, cwd string) source {
+	c.mu.RLock()
+	src, ok := c.m[pc]
+	c.mu.RUnlock()
+	if ok {
+		return src
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	src = source{frame: frame, file: frame.File}
+	if cwd != "" {
+		if ff, err := filepath.Rel(cwd, frame.File); err == nil {
+			src.file = ff
+		}
+	}
+	c.mu.Lock()
+	c.m[pc] = src
+	c.mu.Unlock()
+	return src
+}
@@ -0,0 +1,107 @@
+package console
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Span is a task whose start and end are logged, along with the time it
+// took. Records logged with the span's logger are indented under the
+// opening line, which makes for readable CLI task output:
+//
+//	INF migrate db
+//	INF   create table name=users
+//	INF migrate db done elapsed=1.2s
+type Span struct {
+	logger *slog.Logger
+	inner  *slog.Logger
+	msg    string
+	start  time.Time
+	end    func() // Closes the CI section, if any
+}
+
+// SpanElapsedKey is the key of the attribute holding the duration
+// of a span in its closing line.
+const SpanElapsedKey = "elapsed"
+
+// StartSpan logs msg and args at info level with logger, and returns
+// a Span to be ended with End. If logger's handler is a *Handler, the
+// records logged with the span's logger are indented by one more step.
+func StartSpan(logger *slog.Logger, msg string, args ...any) *Span {
+	s := &Span{
+		logger: logger,
+		inner:  logger,
+		msg:    msg,
+		start:  time.Now(),
+	}
+	if h, ok := logger.Handler().(*Handler); ok {
+		if logger.Enabled(context.Background(), slog.LevelInfo) {
+			s.end = h.openSection(msg)
+		}
+		s.inner = slog.New(h.withIndent(1))
+	}
+	logCaller(logger, slog.LevelInfo, msg, args)
+	return s
+}
+
+// withIndent returns a copy of h whose records are indented by n more
+// steps. Unlike with WithOptions, the copy shares the level and the style
+// of h, so that SetLevel, SetTheme and SetNoColor on h apply to it too.
+func (h *Handler) withIndent(n int) *Handler {
+	opts := h.opts
+	opts.Indent += n
+	s := h.style.p.Load()
+	encOpts := opts
+	if s != nil {
+		s.apply(&encOpts)
+	}
+	c := &Handler{
+		opts:     opts,
+		out:      h.out,
+		groups:   h.groups,
+		ctxAttrs: h.ctxAttrs,
+		rule:     h.rule,
+		enc:      newEncoder(encOpts, h.enc),
+		level:    h.level,
+		pool:     h.pool,
+		mirrors:  h.mirrors,
+		stats:    h.stats,
+		style:    h.style,
+		built:    s,
+	}
+	c.context, c.groups = c.renderContext(c.enc)
+	return c
+}
+
+// Logger returns the logger to use for records belonging to the span.
+func (s *Span) Logger() *slog.Logger {
+	return s.inner
+}
+
+// End logs the end of the span at info level, with the time elapsed
+// since it started, and args. It then closes the span's CI section, if
+// HandlerOptions.CIMarkers is set. The elapsed time is an attribute of
+// the record like args, so it belongs to the groups of the logger given
+// to StartSpan, like "g.elapsed=1.2s".
+func (s *Span) End(args ...any) {
+	args = append(args, Since(s.start))
+	logCaller(s.logger, slog.LevelInfo, s.msg+" done", args)
+	if s.end != nil {
+		s.end()
+	}
+}
+
+// logCaller logs with the caller of the caller of logCaller as the source.
+func logCaller(logger *slog.Logger, level slog.Level, msg string, args []any) {
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // Skip runtime.Callers, logCaller and its caller
+	rec := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	rec.Add(args...)
+	_ = logger.Handler().Handle(ctx, rec)
+}
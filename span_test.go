@@ -0,0 +1,81 @@
+package console
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+)
+
+func TestSpan(t *testing.T) {
+	buf := bytes.Buffer{}
+	logger := slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, AddSource: true}))
+	span := StartSpan(logger, "migrate db", "version", 3)
+	span.Logger().Info("create table", "name", "users")
+	sub := StartSpan(span.Logger(), "seed")
+	sub.Logger().Info("insert")
+	sub.End()
+	span.End("ok", true)
+
+	re := regexp.MustCompile(`^\d{4}-\d\d-\d\d \d\d:\d\d:\d\d INF span_test.go:\d+ > (.*)$`)
+	var msgs []string
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n")) {
+		m := re.FindSubmatch(line)
+		if m == nil {
+			t.Fatalf("unexpected line %q", line)
+		}
+		msgs = append(msgs, string(m[1]))
+	}
+	AssertEqual(t, 6, len(msgs))
+	AssertEqual(t, "migrate db version=3", msgs[0])
+	AssertEqual(t, "  create table name=users", msgs[1])
+	AssertEqual(t, "  seed", msgs[2])
+	AssertEqual(t, "    insert", msgs[3])
+	AssertEqual(t, true, regexp.MustCompile(`^  seed done elapsed=\S+$`).MatchString(msgs[4]))
+	AssertEqual(t, true, regexp.MustCompile(`^migrate db done ok=true elapsed=\S+$`).MatchString(msgs[5]))
+}
+
+func TestSpan_SharesLevelAndStyle(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true})
+	span := StartSpan(slog.New(h), "task")
+	buf.Reset()
+
+	span.Logger().Debug("hidden")
+	h.SetLevel(slog.LevelDebug)
+	span.Logger().Debug("shown")
+	AssertEqual(t, "DBG   shown\n", buf.String())
+
+	buf.Reset()
+	theme := NewDefaultTheme()
+	h.SetNoColor(false)
+	span.Logger().Info("colored")
+	AssertEqual(t, styled("INF", theme.LevelInfo())+"   "+styled("colored", theme.Message())+"\n", buf.String())
+}
+
+func TestSpan_WithGroup(t *testing.T) {
+	elapsed := regexp.MustCompile(`elapsed=[^\s}]+`)
+	for _, tc := range []struct {
+		opts     HandlerOptions
+		attrs    []any
+		expected string
+	}{
+		{HandlerOptions{}, nil, "INF task\nINF   inner g.k=v\nINF task done g.elapsed=X\n"},
+		{HandlerOptions{}, []any{"a", 1}, "INF task g.a=1\nINF   inner g.a=1 g.k=v\nINF task done g.a=1 g.elapsed=X\n"},
+		{HandlerOptions{NestedGroups: true}, nil, "INF task\nINF   inner g={k=v}\nINF task done g={elapsed=X}\n"},
+		{HandlerOptions{NestedGroups: true}, []any{"a", 1}, "INF task g={a=1}\nINF   inner g={a=1 k=v}\nINF task done g={a=1 elapsed=X}\n"},
+		{HandlerOptions{IndentGroups: true}, nil, "INF task\nINF   inner\n  g: k=v\nINF task done\n  g: elapsed=X\n"},
+		{HandlerOptions{IndentGroups: true}, []any{"a", 1}, "INF task\n  g: a=1\nINF   inner\n  g: a=1 k=v\nINF task done\n  g: a=1 elapsed=X\n"},
+	} {
+		buf := bytes.Buffer{}
+		tc.opts.NoColor, tc.opts.HideTime = true, true
+		logger := slog.New(NewHandler(&buf, &tc.opts)).WithGroup("g")
+		if tc.attrs != nil {
+			logger = logger.With(tc.attrs...)
+		}
+		span := StartSpan(logger, "task")
+		span.Logger().Info("inner", "k", "v")
+		span.End()
+		AssertEqual(t, tc.expected, elapsed.ReplaceAllString(buf.String(), "elapsed=X"))
+	}
+}
@@ -0,0 +1,38 @@
+package console
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Elapsed is a duration measuring how long something took. Handlers style
+// it by magnitude: durations under a second are styled as regular values,
+// with Theme.AttrValue, durations under 10 seconds with Theme.LevelWarn,
+// and longer ones with Theme.LevelError.
+type Elapsed time.Duration
+
+func (d Elapsed) String() string {
+	return time.Duration(d).String()
+}
+
+// Since returns an attribute with key SpanElapsedKey holding the time
+// elapsed since start, as an Elapsed:
+//
+//	start := time.Now()
+//	build()
+//	logger.Info("build done", console.Since(start))
+func Since(start time.Time) slog.Attr {
+	return slog.Any(SpanElapsedKey, Elapsed(time.Since(start)))
+}
+
+// elapsedStyle returns the style of the elapsed duration d.
+func (e *encoder) elapsedStyle(d Elapsed) ANSIMod {
+	switch {
+	case time.Duration(d) >= 10*time.Second:
+		return e.opts.Theme.LevelError()
+	case time.Duration(d) >= time.Second:
+		return e.opts.Theme.LevelWarn()
+	default:
+		return e.opts.Theme.AttrValue()
+	}
+}
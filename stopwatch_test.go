@@ -0,0 +1,38 @@
+package console
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSince(t *testing.T) {
+	a := Since(time.Now().Add(-time.Minute))
+	AssertEqual(t, SpanElapsedKey, a.Key)
+	d, ok := a.Value.Any().(Elapsed)
+	AssertEqual(t, true, ok)
+	AssertEqual(t, true, time.Duration(d) >= time.Minute)
+}
+
+func TestHandler_Elapsed(t *testing.T) {
+	theme := NewDefaultTheme()
+	for _, tc := range []struct {
+		d     time.Duration
+		style ANSIMod
+	}{
+		{150 * time.Millisecond, theme.AttrValue()},
+		{2 * time.Second, theme.LevelWarn()},
+		{time.Minute, theme.LevelError()},
+	} {
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "done", 0)
+		rec.Add("took", Elapsed(tc.d))
+		AssertEqual(t, "INF done took="+tc.d.String()+"\n", string(Render(rec, &HandlerOptions{NoColor: true})))
+		out := string(Render(rec, &HandlerOptions{Theme: theme}))
+		val := tc.d.String()
+		if tc.style != "" {
+			val = string(tc.style) + val + string(ResetMod)
+		}
+		AssertEqual(t, true, strings.HasSuffix(out, val+"\n"))
+	}
+}
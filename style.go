@@ -0,0 +1,108 @@
+package console
+
+import "sync/atomic"
+
+// styleVar holds the style set at runtime with SetTheme and SetNoColor,
+// shared by a handler and the handlers derived from it with WithAttrs
+// and WithGroup.
+type styleVar struct {
+	p atomic.Pointer[style]
+}
+
+// style overrides the styling options of a handler.
+type style struct {
+	theme   Theme
+	noColor bool
+}
+
+// apply overrides the options o with s.
+func (s *style) apply(o *HandlerOptions) {
+	o.Theme = s.theme
+	o.NoColor = s.noColor || o.Plain
+	o.applyProfile()
+}
+
+// rendering is the encoder and the pre-rendered context of a handler,
+// rendered again for a style set at runtime.
+type rendering struct {
+	style   *style
+	enc     *encoder
+	context buffer
+}
+
+// rendering returns the encoder and the pre-rendered context attributes
+// to use with the current style, along with that style. They are rendered
+// again, once, when the style changes.
+func (h *Handler) rendering() (*encoder, *buffer, *style) {
+	if s := h.style.p.Load(); s != h.built {
+		return h.rerender(s)
+	}
+	return h.enc, &h.context, h.built
+}
+
+// rerender returns the encoder and the context of h rendered with the
+// style s, set at runtime.
+func (h *Handler) rerender(s *style) (*encoder, *buffer, *style) {
+	if r := h.cache.Load(); r != nil && r.style == s {
+		return r.enc, &r.context, s
+	}
+	opts := h.opts
+	s.apply(&opts)
+	r := &rendering{style: s, enc: newEncoder(opts, h.enc)}
+	r.context, _ = h.renderContext(r.enc)
+	h.cache.Store(r)
+	return r.enc, &r.context, s
+}
+
+// renderContext renders the context attributes of h with enc, and
+// returns them along with the groups of h, opened accordingly.
+func (h *Handler) renderContext(enc *encoder) (buffer, groups) {
+	var ctx buffer
+	g := h.groups
+	g.opened = 0
+	g.pad = 0
+	var pe encoder
+	enc = enc.withPad(&g.pad, &pe)
+	for _, a := range h.ctxAttrs {
+		// Later attributes are in the same or deeper groups
+		a.groups.opened = g.opened
+		enc.writeGroupedAttr(&ctx, a.attr, &a.groups)
+		g.opened = a.groups.opened
+	}
+	ctx.Clip()
+	return ctx, g
+}
+
+// SetTheme changes the theme of h, and of every handler derived from it
+// with WithAttrs and WithGroup, without losing their attributes and
+// groups. It is safe for concurrent use. If t is nil, the default theme
+// is used.
+func (h *Handler) SetTheme(t Theme) {
+	if t == nil {
+		t = NewDefaultTheme()
+	}
+	h.updateStyle(func(s *style) { s.theme = t })
+}
+
+// SetNoColor enables or disables colors for h, and for every handler
+// derived from it with WithAttrs and WithGroup, like after redirecting
+// their output with SetOutput. It is safe for concurrent use.
+func (h *Handler) SetNoColor(noColor bool) {
+	h.updateStyle(func(s *style) { s.noColor = noColor })
+}
+
+// updateStyle atomically replaces the style set at runtime
+// with a copy modified by fn.
+func (h *Handler) updateStyle(fn func(*style)) {
+	for {
+		old := h.style.p.Load()
+		s := style{theme: h.opts.Theme, noColor: h.opts.NoColor}
+		if old != nil {
+			s = *old
+		}
+		fn(&s)
+		if h.style.p.CompareAndSwap(old, &s) {
+			return
+		}
+	}
+}
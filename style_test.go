@@ -0,0 +1,81 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandler_SetTheme(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{HideLevel: true})
+	child := h.WithAttrs([]slog.Attr{slog.String("k", "v")}).WithGroup("g").(*Handler)
+	light := NewLightTheme()
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("n", 1)
+	full := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	full.Add("k", "v", slog.Group("g", "n", 1))
+	expected := string(Render(full, &HandlerOptions{HideLevel: true, Theme: light}))
+
+	h.SetTheme(light)
+	AssertNoError(t, child.Handle(context.Background(), rec))
+	AssertEqual(t, expected, buf.String())
+	AssertEqual(t, "Light", child.Options().Theme.Name())
+
+	buf.Reset()
+	child.WithAttrs([]slog.Attr{slog.Int("x", 2)}).(*Handler).SetTheme(nil)
+	AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)))
+	AssertEqual(t, string(Render(slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0), &HandlerOptions{HideLevel: true})), buf.String())
+
+	// The theme of handlers created with WithOptions is not affected
+	other := h.WithOptions(func(o *HandlerOptions) { o.NoColor = true })
+	h.SetTheme(light)
+	buf.Reset()
+	AssertNoError(t, other.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)))
+	AssertEqual(t, "msg\n", buf.String())
+}
+
+func TestHandler_SetNoColor(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, Theme: NewLightTheme()})
+	child := h.WithAttrs([]slog.Attr{slog.String("k", "v")})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+
+	h.SetNoColor(false)
+	AssertNoError(t, child.Handle(context.Background(), rec))
+	rec.Add("k", "v")
+	AssertEqual(t, string(Render(rec, &HandlerOptions{Theme: NewLightTheme()})), buf.String())
+
+	buf.Reset()
+	h.SetNoColor(true)
+	h.SetTheme(NewBrightTheme())
+	AssertNoError(t, child.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)))
+	AssertEqual(t, "INF msg k=v\n", buf.String())
+	AssertEqual(t, true, h.Options().NoColor)
+	AssertEqual(t, "Bright", h.Options().Theme.Name())
+}
+
+func TestHandler_SetTheme_Concurrent(t *testing.T) {
+	h := NewHandler(&bytes.Buffer{}, nil)
+	logger := slog.New(h).With("k", "v")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("msg", "j", j)
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		h.SetTheme(NewBrightTheme())
+		h.SetTheme(NewDefaultTheme())
+		h.SetNoColor(j%2 == 0)
+	}
+	wg.Wait()
+}
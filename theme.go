@@ -1,8 +1,10 @@
 package console
 
 import (
-	"fmt"
 	"log/slog"
+	"os"
+	"strconv"
+	"strings"
 )
 
 type ANSIMod string
@@ -54,11 +56,62 @@ func ToANSICode(modes ...int) ANSIMod {
 		if i > 0 {
 			s += ";"
 		}
-		s += fmt.Sprintf("%d", m)
+		s += strconv.Itoa(m)
 	}
 	return ANSIMod("\x1b[" + s + "m")
 }
 
+// RGB returns the mode setting the 24-bit foreground color r, g, b, for
+// terminals supporting true colors. It can be combined with other modes
+// by concatenation, like ToANSICode(Bold) + RGB(255, 136, 0).
+func RGB(r, g, b uint8) ANSIMod {
+	return ToANSICode(38, 2, int(r), int(g), int(b))
+}
+
+// Hex returns the mode setting the 24-bit foreground color given in CSS
+// hexadecimal notation, like "#ff8800" or "#f80". The leading '#' is
+// optional. It returns an empty mode, which doesn't change the style,
+// if color is not valid.
+func Hex(color string) ANSIMod {
+	color = strings.TrimPrefix(color, "#")
+	if len(color) == 3 {
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	}
+	if len(color) != 6 {
+		return ""
+	}
+	v, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return ""
+	}
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// Color256 returns the mode setting the foreground color n of the
+// xterm 256 colors palette, for terminals not supporting true colors.
+// Colors 0 to 15 are the basic colors and their bright variants, 16 to 231
+// a 6x6x6 color cube, and 232 to 255 a grayscale ramp.
+func Color256(n uint8) ANSIMod {
+	return ToANSICode(38, 5, int(n))
+}
+
+// xterm256RGB returns the components of the color n of the xterm 256
+// colors palette.
+func xterm256RGB(n uint8) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		v, _ := strconv.ParseUint(htmlColors[n][1:], 16, 32)
+		return uint8(v >> 16), uint8(v >> 8), uint8(v)
+	case n < 232:
+		levels := [6]uint8{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return levels[n/36], levels[n/6%6], levels[n%6]
+	default:
+		v := 8 + 10*(n-232)
+		return v, v, v
+	}
+}
+
 type Theme interface {
 	Name() string
 	Timestamp() ANSIMod
@@ -76,33 +129,143 @@ type Theme interface {
 	Level(level slog.Level) ANSIMod
 }
 
+// The interfaces below are optionally implemented by a Theme to style
+// more parts of the output. When a theme doesn't implement one, these
+// parts fall back to related styles, like with NewThemeFromSpec.
+// ThemeDef implements all of them.
+type (
+	// AttrGroupTheme styles the group prefix of attribute keys.
+	// It defaults to Theme.AttrKey.
+	AttrGroupTheme interface {
+		AttrGroup() ANSIMod
+	}
+	// SourceSeparatorTheme styles the separator following the source.
+	// It defaults to Theme.Source.
+	SourceSeparatorTheme interface {
+		SourceSeparator() ANSIMod
+	}
+	// PunctuationTheme styles the structural characters, like '=', the
+	// braces of NestedGroups and the dots between group names. They are
+	// styled like the preceding key by default.
+	PunctuationTheme interface {
+		Punctuation() ANSIMod
+	}
+	// DiffTheme styles the added and removed lines of values looking like
+	// unified diffs. They default to Theme.LevelInfo and Theme.LevelError.
+	DiffTheme interface {
+		DiffAdded() ANSIMod
+		DiffRemoved() ANSIMod
+	}
+	// ChangesTheme styles the values which changed, or not, with
+	// HandlerOptions.HighlightChanges. They default to Theme.AttrValue.
+	ChangesTheme interface {
+		AttrValueChanged() ANSIMod
+		AttrValueUnchanged() ANSIMod
+	}
+)
+
+func attrGroupStyle(t Theme) ANSIMod {
+	if t, ok := t.(AttrGroupTheme); ok {
+		return t.AttrGroup()
+	}
+	return t.AttrKey()
+}
+
+func sourceSeparatorStyle(t Theme) ANSIMod {
+	if t, ok := t.(SourceSeparatorTheme); ok {
+		return t.SourceSeparator()
+	}
+	return t.Source()
+}
+
+func punctuationStyle(t Theme) ANSIMod {
+	if t, ok := t.(PunctuationTheme); ok {
+		return t.Punctuation()
+	}
+	return ""
+}
+
+func diffAddedStyle(t Theme) ANSIMod {
+	if t, ok := t.(DiffTheme); ok {
+		return t.DiffAdded()
+	}
+	return t.LevelInfo()
+}
+
+func diffRemovedStyle(t Theme) ANSIMod {
+	if t, ok := t.(DiffTheme); ok {
+		return t.DiffRemoved()
+	}
+	return t.LevelError()
+}
+
+func changedStyle(t Theme, changed bool) ANSIMod {
+	t2, ok := t.(ChangesTheme)
+	switch {
+	case !ok:
+		return t.AttrValue()
+	case changed:
+		return t2.AttrValueChanged()
+	default:
+		return t2.AttrValueUnchanged()
+	}
+}
+
+// wrappedTheme embeds a Theme and forwards the optional styles it
+// implements, for themes overriding some styles of another one.
+type wrappedTheme struct {
+	Theme
+}
+
+func (t wrappedTheme) AttrGroup() ANSIMod          { return attrGroupStyle(t.Theme) }
+func (t wrappedTheme) SourceSeparator() ANSIMod    { return sourceSeparatorStyle(t.Theme) }
+func (t wrappedTheme) Punctuation() ANSIMod        { return punctuationStyle(t.Theme) }
+func (t wrappedTheme) DiffAdded() ANSIMod          { return diffAddedStyle(t.Theme) }
+func (t wrappedTheme) DiffRemoved() ANSIMod        { return diffRemovedStyle(t.Theme) }
+func (t wrappedTheme) AttrValueChanged() ANSIMod   { return changedStyle(t.Theme, true) }
+func (t wrappedTheme) AttrValueUnchanged() ANSIMod { return changedStyle(t.Theme, false) }
+
 type ThemeDef struct {
-	name           string
-	timestamp      ANSIMod
-	source         ANSIMod
-	message        ANSIMod
-	messageDebug   ANSIMod
-	attrKey        ANSIMod
-	attrValue      ANSIMod
-	attrValueError ANSIMod
-	levelError     ANSIMod
-	levelWarn      ANSIMod
-	levelInfo      ANSIMod
-	levelDebug     ANSIMod
-}
-
-func (t ThemeDef) Name() string            { return t.name }
-func (t ThemeDef) Timestamp() ANSIMod      { return t.timestamp }
-func (t ThemeDef) Source() ANSIMod         { return t.source }
-func (t ThemeDef) Message() ANSIMod        { return t.message }
-func (t ThemeDef) MessageDebug() ANSIMod   { return t.messageDebug }
-func (t ThemeDef) AttrKey() ANSIMod        { return t.attrKey }
-func (t ThemeDef) AttrValue() ANSIMod      { return t.attrValue }
-func (t ThemeDef) AttrValueError() ANSIMod { return t.attrValueError }
-func (t ThemeDef) LevelError() ANSIMod     { return t.levelError }
-func (t ThemeDef) LevelWarn() ANSIMod      { return t.levelWarn }
-func (t ThemeDef) LevelInfo() ANSIMod      { return t.levelInfo }
-func (t ThemeDef) LevelDebug() ANSIMod     { return t.levelDebug }
+	name               string
+	timestamp          ANSIMod
+	source             ANSIMod
+	sourceSeparator    ANSIMod
+	message            ANSIMod
+	messageDebug       ANSIMod
+	attrKey            ANSIMod
+	attrGroup          ANSIMod
+	attrValue          ANSIMod
+	attrValueError     ANSIMod
+	attrValueChanged   ANSIMod
+	attrValueUnchanged ANSIMod
+	punctuation        ANSIMod
+	diffAdded          ANSIMod
+	diffRemoved        ANSIMod
+	levelError         ANSIMod
+	levelWarn          ANSIMod
+	levelInfo          ANSIMod
+	levelDebug         ANSIMod
+}
+
+func (t ThemeDef) Name() string                { return t.name }
+func (t ThemeDef) Timestamp() ANSIMod          { return t.timestamp }
+func (t ThemeDef) Source() ANSIMod             { return t.source }
+func (t ThemeDef) SourceSeparator() ANSIMod    { return t.sourceSeparator }
+func (t ThemeDef) Message() ANSIMod            { return t.message }
+func (t ThemeDef) MessageDebug() ANSIMod       { return t.messageDebug }
+func (t ThemeDef) AttrKey() ANSIMod            { return t.attrKey }
+func (t ThemeDef) AttrGroup() ANSIMod          { return t.attrGroup }
+func (t ThemeDef) AttrValue() ANSIMod          { return t.attrValue }
+func (t ThemeDef) AttrValueError() ANSIMod     { return t.attrValueError }
+func (t ThemeDef) AttrValueChanged() ANSIMod   { return t.attrValueChanged }
+func (t ThemeDef) AttrValueUnchanged() ANSIMod { return t.attrValueUnchanged }
+func (t ThemeDef) Punctuation() ANSIMod        { return t.punctuation }
+func (t ThemeDef) DiffAdded() ANSIMod          { return t.diffAdded }
+func (t ThemeDef) DiffRemoved() ANSIMod        { return t.diffRemoved }
+func (t ThemeDef) LevelError() ANSIMod         { return t.levelError }
+func (t ThemeDef) LevelWarn() ANSIMod          { return t.levelWarn }
+func (t ThemeDef) LevelInfo() ANSIMod          { return t.levelInfo }
+func (t ThemeDef) LevelDebug() ANSIMod         { return t.levelDebug }
 func (t ThemeDef) Level(level slog.Level) ANSIMod {
 	switch {
 	case level >= slog.LevelError:
@@ -116,36 +279,366 @@ func (t ThemeDef) Level(level slog.Level) ANSIMod {
 	}
 }
 
+// ThemeSpec declares the styles of a theme, to create one with
+// NewThemeFromSpec without implementing the Theme interface.
+// Unset styles fall back to related ones, as documented, or to no style.
+type ThemeSpec struct {
+	Name               string // Defaults to "Custom"
+	Timestamp          ANSIMod
+	Source             ANSIMod // Defaults to Timestamp
+	SourceSeparator    ANSIMod // Defaults to Source
+	Message            ANSIMod
+	MessageDebug       ANSIMod // Defaults to Message
+	AttrKey            ANSIMod
+	AttrGroup          ANSIMod // Defaults to AttrKey
+	AttrValue          ANSIMod
+	AttrValueError     ANSIMod // Defaults to LevelError
+	AttrValueChanged   ANSIMod // Defaults to AttrValue
+	AttrValueUnchanged ANSIMod // Defaults to AttrValue
+	Punctuation        ANSIMod
+	DiffAdded          ANSIMod // Defaults to LevelInfo
+	DiffRemoved        ANSIMod // Defaults to LevelError
+	LevelError         ANSIMod
+	LevelWarn          ANSIMod
+	LevelInfo          ANSIMod
+	LevelDebug         ANSIMod
+}
+
+// NewThemeFromSpec creates a theme with the styles of spec:
+//
+//	theme := console.NewThemeFromSpec(console.ThemeSpec{
+//		Name:       "Ocean",
+//		Timestamp:  console.ToANSICode(console.Faint),
+//		AttrKey:    console.Hex("#56b6c2"),
+//		LevelError: console.ToANSICode(console.Bold, console.Red),
+//		LevelWarn:  console.ToANSICode(console.Yellow),
+//		LevelInfo:  console.ToANSICode(console.Blue),
+//	})
+func NewThemeFromSpec(spec ThemeSpec) Theme {
+	or := func(m, fallback ANSIMod) ANSIMod {
+		if m == "" {
+			return fallback
+		}
+		return m
+	}
+	name := spec.Name
+	if name == "" {
+		name = "Custom"
+	}
+	source := or(spec.Source, spec.Timestamp)
+	return ThemeDef{
+		name:               name,
+		timestamp:          spec.Timestamp,
+		source:             source,
+		sourceSeparator:    or(spec.SourceSeparator, source),
+		message:            spec.Message,
+		messageDebug:       or(spec.MessageDebug, spec.Message),
+		attrKey:            spec.AttrKey,
+		attrGroup:          or(spec.AttrGroup, spec.AttrKey),
+		attrValue:          spec.AttrValue,
+		attrValueError:     or(spec.AttrValueError, spec.LevelError),
+		attrValueChanged:   or(spec.AttrValueChanged, spec.AttrValue),
+		attrValueUnchanged: or(spec.AttrValueUnchanged, spec.AttrValue),
+		punctuation:        spec.Punctuation,
+		diffAdded:          or(spec.DiffAdded, spec.LevelInfo),
+		diffRemoved:        or(spec.DiffRemoved, spec.LevelError),
+		levelError:         spec.LevelError,
+		levelWarn:          spec.LevelWarn,
+		levelInfo:          spec.LevelInfo,
+		levelDebug:         spec.LevelDebug,
+	}
+}
+
 func NewDefaultTheme() Theme {
 	return ThemeDef{
-		name:           "Default",
-		timestamp:      ToANSICode(BrightBlack),
-		source:         ToANSICode(Bold, BrightBlack),
-		message:        ToANSICode(Bold),
-		messageDebug:   ToANSICode(),
-		attrKey:        ToANSICode(Cyan),
-		attrValue:      ToANSICode(),
-		attrValueError: ToANSICode(Bold, Red),
-		levelError:     ToANSICode(Red),
-		levelWarn:      ToANSICode(Yellow),
-		levelInfo:      ToANSICode(Green),
-		levelDebug:     ToANSICode(),
+		name:               "Default",
+		timestamp:          ToANSICode(BrightBlack),
+		source:             ToANSICode(Bold, BrightBlack),
+		sourceSeparator:    ToANSICode(Cyan),
+		message:            ToANSICode(Bold),
+		messageDebug:       ToANSICode(),
+		attrKey:            ToANSICode(Cyan),
+		attrGroup:          ToANSICode(Faint, Cyan),
+		attrValue:          ToANSICode(),
+		attrValueError:     ToANSICode(Bold, Red),
+		attrValueChanged:   ToANSICode(Bold, Yellow),
+		attrValueUnchanged: ToANSICode(Faint),
+		punctuation:        ToANSICode(),
+		diffAdded:          ToANSICode(Green),
+		diffRemoved:        ToANSICode(Red),
+		levelError:         ToANSICode(Red),
+		levelWarn:          ToANSICode(Yellow),
+		levelInfo:          ToANSICode(Green),
+		levelDebug:         ToANSICode(),
 	}
 }
 
 func NewBrightTheme() Theme {
 	return ThemeDef{
-		name:           "Bright",
-		timestamp:      ToANSICode(Gray),
-		source:         ToANSICode(Bold, Gray),
-		message:        ToANSICode(Bold, White),
-		messageDebug:   ToANSICode(),
-		attrKey:        ToANSICode(BrightCyan),
-		attrValue:      ToANSICode(),
-		attrValueError: ToANSICode(Bold, BrightRed),
-		levelError:     ToANSICode(BrightRed),
-		levelWarn:      ToANSICode(BrightYellow),
-		levelInfo:      ToANSICode(BrightGreen),
-		levelDebug:     ToANSICode(),
+		name:               "Bright",
+		timestamp:          ToANSICode(Gray),
+		source:             ToANSICode(Bold, Gray),
+		sourceSeparator:    ToANSICode(BrightCyan),
+		message:            ToANSICode(Bold, White),
+		messageDebug:       ToANSICode(),
+		attrKey:            ToANSICode(BrightCyan),
+		attrGroup:          ToANSICode(Cyan),
+		attrValue:          ToANSICode(),
+		attrValueError:     ToANSICode(Bold, BrightRed),
+		attrValueChanged:   ToANSICode(Bold, BrightYellow),
+		attrValueUnchanged: ToANSICode(Gray),
+		punctuation:        ToANSICode(Gray),
+		diffAdded:          ToANSICode(BrightGreen),
+		diffRemoved:        ToANSICode(BrightRed),
+		levelError:         ToANSICode(BrightRed),
+		levelWarn:          ToANSICode(BrightYellow),
+		levelInfo:          ToANSICode(BrightGreen),
+		levelDebug:         ToANSICode(),
+	}
+}
+
+// NewTrueColorTheme returns a theme using 24-bit colors, for terminals
+// supporting them.
+func NewTrueColorTheme() Theme {
+	return ThemeDef{
+		name:               "TrueColor",
+		timestamp:          Hex("#7f848e"),
+		source:             ToANSICode(Bold) + Hex("#7f848e"),
+		sourceSeparator:    Hex("#56b6c2"),
+		message:            ToANSICode(Bold),
+		messageDebug:       ToANSICode(),
+		attrKey:            Hex("#56b6c2"),
+		attrGroup:          ToANSICode(Faint) + Hex("#56b6c2"),
+		attrValue:          ToANSICode(),
+		attrValueError:     ToANSICode(Bold) + Hex("#e06c75"),
+		attrValueChanged:   ToANSICode(Bold) + Hex("#e5c07b"),
+		attrValueUnchanged: ToANSICode(Faint),
+		punctuation:        Hex("#5c6370"),
+		diffAdded:          Hex("#98c379"),
+		diffRemoved:        Hex("#e06c75"),
+		levelError:         Hex("#e06c75"),
+		levelWarn:          Hex("#e5c07b"),
+		levelInfo:          Hex("#98c379"),
+		levelDebug:         Hex("#61afef"),
 	}
 }
+
+// New256Theme returns a theme using colors of the xterm 256 colors
+// palette, richer than the basic ones, for terminals not supporting
+// true colors.
+func New256Theme() Theme {
+	return ThemeDef{
+		name:               "256",
+		timestamp:          Color256(245),
+		source:             ToANSICode(Bold) + Color256(245),
+		sourceSeparator:    Color256(37),
+		message:            ToANSICode(Bold),
+		messageDebug:       ToANSICode(),
+		attrKey:            Color256(74),
+		attrGroup:          ToANSICode(Faint) + Color256(74),
+		attrValue:          ToANSICode(),
+		attrValueError:     ToANSICode(Bold) + Color256(203),
+		attrValueChanged:   ToANSICode(Bold) + Color256(221),
+		attrValueUnchanged: ToANSICode(Faint),
+		punctuation:        Color256(240),
+		diffAdded:          Color256(114),
+		diffRemoved:        Color256(203),
+		levelError:         Color256(203),
+		levelWarn:          Color256(221),
+		levelInfo:          Color256(114),
+		levelDebug:         Color256(110),
+	}
+}
+
+// NewLightTheme returns a theme readable on terminals with a light
+// background, where the default themes' bright colors are washed out.
+func NewLightTheme() Theme {
+	return ThemeDef{
+		name:               "Light",
+		timestamp:          ToANSICode(BrightBlack),
+		source:             ToANSICode(Bold, BrightBlack),
+		sourceSeparator:    ToANSICode(Blue),
+		message:            ToANSICode(Bold),
+		messageDebug:       ToANSICode(),
+		attrKey:            ToANSICode(Blue),
+		attrGroup:          ToANSICode(Faint, Blue),
+		attrValue:          ToANSICode(),
+		attrValueError:     ToANSICode(Bold, Red),
+		attrValueChanged:   ToANSICode(Bold, Magenta),
+		attrValueUnchanged: ToANSICode(Faint),
+		punctuation:        ToANSICode(BrightBlack),
+		diffAdded:          ToANSICode(Green),
+		diffRemoved:        ToANSICode(Red),
+		levelError:         ToANSICode(Bold, Red),
+		levelWarn:          ToANSICode(Bold, Yellow),
+		levelInfo:          ToANSICode(Green),
+		levelDebug:         ToANSICode(),
+	}
+}
+
+// IsLightBackground reports whether the terminal has a light background,
+// as reported by the COLORFGBG environment variable set by some terminals,
+// like "0;15" for black on white. It returns false if the background is
+// unknown, as most terminals have a dark one.
+func IsLightBackground() bool {
+	v := os.Getenv("COLORFGBG")
+	if v == "" {
+		return false
+	}
+	bg, err := strconv.Atoi(v[strings.LastIndexByte(v, ';')+1:])
+	// Colors 7 (light gray) and 9 to 15 (bright variants) are light
+	return err == nil && (bg == 7 || (bg >= 9 && bg <= 15))
+}
+
+// NewAutoTheme returns NewLightTheme if the terminal has a light
+// background according to IsLightBackground, or NewDefaultTheme.
+func NewAutoTheme() Theme {
+	if IsLightBackground() {
+		return NewLightTheme()
+	}
+	return NewDefaultTheme()
+}
+
+// NewDraculaTheme returns a theme with the 24-bit colors of the Dracula
+// palette.
+func NewDraculaTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "Dracula",
+		Timestamp:          Hex("#6272a4"),
+		Source:             ToANSICode(Bold) + Hex("#6272a4"),
+		SourceSeparator:    Hex("#ff79c6"),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            Hex("#bd93f9"),
+		AttrGroup:          ToANSICode(Faint) + Hex("#bd93f9"),
+		AttrValueError:     ToANSICode(Bold) + Hex("#ff5555"),
+		AttrValueChanged:   ToANSICode(Bold) + Hex("#ffb86c"),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        Hex("#6272a4"),
+		LevelError:         Hex("#ff5555"),
+		LevelWarn:          Hex("#f1fa8c"),
+		LevelInfo:          Hex("#50fa7b"),
+		LevelDebug:         Hex("#8be9fd"),
+	})
+}
+
+// NewSolarizedDarkTheme returns a theme with the 24-bit colors of the
+// Solarized palette, for a dark background.
+func NewSolarizedDarkTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "SolarizedDark",
+		Timestamp:          Hex("#586e75"),
+		Source:             ToANSICode(Bold) + Hex("#586e75"),
+		SourceSeparator:    Hex("#2aa198"),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            Hex("#268bd2"),
+		AttrGroup:          ToANSICode(Faint) + Hex("#268bd2"),
+		AttrValueError:     ToANSICode(Bold) + Hex("#dc322f"),
+		AttrValueChanged:   ToANSICode(Bold) + Hex("#cb4b16"),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        Hex("#586e75"),
+		DiffAdded:          Hex("#859900"),
+		LevelError:         Hex("#dc322f"),
+		LevelWarn:          Hex("#b58900"),
+		LevelInfo:          Hex("#859900"),
+		LevelDebug:         Hex("#6c71c4"),
+	})
+}
+
+// NewNordTheme returns a theme with the 24-bit colors of the Nord palette.
+func NewNordTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "Nord",
+		Timestamp:          Hex("#616e88"),
+		Source:             ToANSICode(Bold) + Hex("#616e88"),
+		SourceSeparator:    Hex("#88c0d0"),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            Hex("#88c0d0"),
+		AttrGroup:          Hex("#81a1c1"),
+		AttrValueError:     ToANSICode(Bold) + Hex("#bf616a"),
+		AttrValueChanged:   ToANSICode(Bold) + Hex("#d08770"),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        Hex("#616e88"),
+		LevelError:         Hex("#bf616a"),
+		LevelWarn:          Hex("#ebcb8b"),
+		LevelInfo:          Hex("#a3be8c"),
+		LevelDebug:         Hex("#b48ead"),
+	})
+}
+
+// NewMonokaiTheme returns a theme with the 24-bit colors of the Monokai
+// palette.
+func NewMonokaiTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "Monokai",
+		Timestamp:          Hex("#75715e"),
+		Source:             ToANSICode(Bold) + Hex("#75715e"),
+		SourceSeparator:    Hex("#f92672"),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            Hex("#66d9ef"),
+		AttrGroup:          ToANSICode(Faint) + Hex("#66d9ef"),
+		AttrValueError:     ToANSICode(Bold) + Hex("#f92672"),
+		AttrValueChanged:   ToANSICode(Bold) + Hex("#fd971f"),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        Hex("#75715e"),
+		LevelError:         Hex("#f92672"),
+		LevelWarn:          Hex("#fd971f"),
+		LevelInfo:          Hex("#a6e22e"),
+		LevelDebug:         Hex("#ae81ff"),
+	})
+}
+
+// NewColorblindTheme returns a theme readable with deuteranopia or
+// protanopia, using the 24-bit colors of the Okabe-Ito palette. Levels,
+// errors and diffs are told apart with blue and orange hues rather than
+// green and red ones.
+func NewColorblindTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "Colorblind",
+		Timestamp:          ToANSICode(BrightBlack),
+		Source:             ToANSICode(Bold, BrightBlack),
+		SourceSeparator:    Hex("#56b4e9"),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            Hex("#56b4e9"),
+		AttrGroup:          ToANSICode(Faint) + Hex("#56b4e9"),
+		AttrValueError:     ToANSICode(Bold) + Hex("#d55e00"),
+		AttrValueChanged:   ToANSICode(Bold) + Hex("#f0e442"),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        ToANSICode(BrightBlack),
+		DiffAdded:          Hex("#0072b2"),
+		DiffRemoved:        Hex("#e69f00"),
+		LevelError:         ToANSICode(Bold) + Hex("#d55e00"),
+		LevelWarn:          Hex("#e69f00"),
+		LevelInfo:          Hex("#0072b2"),
+		LevelDebug:         Hex("#cc79a7"),
+	})
+}
+
+// NewColorblindBasicTheme is like NewColorblindTheme, with the basic
+// colors supported by all terminals.
+func NewColorblindBasicTheme() Theme {
+	return NewThemeFromSpec(ThemeSpec{
+		Name:               "ColorblindBasic",
+		Timestamp:          ToANSICode(BrightBlack),
+		Source:             ToANSICode(Bold, BrightBlack),
+		SourceSeparator:    ToANSICode(Cyan),
+		Message:            ToANSICode(Bold),
+		MessageDebug:       ToANSICode(),
+		AttrKey:            ToANSICode(Cyan),
+		AttrGroup:          ToANSICode(Faint, Cyan),
+		AttrValueError:     ToANSICode(Bold, Underline, BrightYellow),
+		AttrValueChanged:   ToANSICode(Bold, Magenta),
+		AttrValueUnchanged: ToANSICode(Faint),
+		Punctuation:        ToANSICode(BrightBlack),
+		DiffAdded:          ToANSICode(Blue),
+		DiffRemoved:        ToANSICode(Yellow),
+		LevelError:         ToANSICode(Bold, Underline, BrightYellow),
+		LevelWarn:          ToANSICode(Yellow),
+		LevelInfo:          ToANSICode(BrightBlue),
+		LevelDebug:         ToANSICode(Magenta),
+	})
+}
@@ -0,0 +1,117 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHex(t *testing.T) {
+	AssertEqual(t, ANSIMod("\x1b[38;2;255;136;0m"), Hex("#ff8800"))
+	AssertEqual(t, Hex("#ff8800"), Hex("FF8800"))
+	AssertEqual(t, Hex("#ff8800"), Hex("#f80"))
+	AssertEqual(t, RGB(255, 136, 0), Hex("#ff8800"))
+	AssertEqual(t, ANSIMod(""), Hex("#ff88"))
+	AssertEqual(t, ANSIMod(""), Hex("#gg8800"))
+}
+
+func TestColor256(t *testing.T) {
+	AssertEqual(t, ANSIMod("\x1b[38;5;208m"), Color256(208))
+	for n, expected := range map[uint8]string{
+		1:   "#cd3131",
+		16:  "#000000",
+		208: "#ff8700",
+		231: "#ffffff",
+		232: "#080808",
+		255: "#eeeeee",
+	} {
+		r, g, b := xterm256RGB(n)
+		AssertEqual(t, expected, string(appendHexColor(nil, r, g, b)))
+	}
+}
+
+func TestHandler_MaxValueLength_TrueColor(t *testing.T) {
+	theme := NewTrueColorTheme()
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{MaxValueLength: 5, Theme: theme})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("err", errors.New("the error"))
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, true, strings.HasSuffix(buf.String(), string(theme.AttrValueError())+"the e"+string(ResetMod)+"...[truncated 4 bytes]\n"))
+}
+
+func TestNewThemeFromSpec(t *testing.T) {
+	theme := NewThemeFromSpec(ThemeSpec{
+		Timestamp:  ToANSICode(Faint),
+		Message:    ToANSICode(Bold),
+		AttrKey:    Hex("#56b6c2"),
+		AttrValue:  ToANSICode(Italic),
+		LevelError: ToANSICode(Red),
+		LevelInfo:  ToANSICode(Blue),
+		DiffAdded:  ToANSICode(Green),
+	})
+	AssertEqual(t, "Custom", theme.Name())
+	AssertEqual(t, ToANSICode(Faint), theme.Source())
+	AssertEqual(t, ToANSICode(Faint), sourceSeparatorStyle(theme))
+	AssertEqual(t, ToANSICode(Bold), theme.MessageDebug())
+	AssertEqual(t, Hex("#56b6c2"), attrGroupStyle(theme))
+	AssertEqual(t, ToANSICode(Red), theme.AttrValueError())
+	AssertEqual(t, ToANSICode(Italic), changedStyle(theme, true))
+	AssertEqual(t, ToANSICode(Green), diffAddedStyle(theme))
+	AssertEqual(t, ToANSICode(Red), diffRemovedStyle(theme))
+	AssertEqual(t, ANSIMod(""), punctuationStyle(theme))
+	AssertEqual(t, ANSIMod(""), theme.LevelWarn())
+	AssertEqual(t, ToANSICode(Blue), theme.Level(slog.LevelInfo+1))
+	AssertEqual(t, "Ocean", NewThemeFromSpec(ThemeSpec{Name: "Ocean"}).Name())
+}
+
+func TestIsLightBackground(t *testing.T) {
+	for value, light := range map[string]bool{
+		"":        false,
+		"15;0":    false,
+		"0;15":    true,
+		"0;7":     true,
+		"0;8":     false,
+		"12;12;0": false,
+		"0;def;7": true,
+		"garbage": false,
+	} {
+		t.Setenv("COLORFGBG", value)
+		AssertEqual(t, light, IsLightBackground())
+		if light {
+			AssertEqual(t, "Light", NewAutoTheme().Name())
+		} else {
+			AssertEqual(t, "Default", NewAutoTheme().Name())
+		}
+	}
+}
+
+// baseTheme implements only the Theme interface, like themes
+// written before the optional styles were introduced.
+type baseTheme struct {
+	Theme
+}
+
+func TestOptionalThemeStyles(t *testing.T) {
+	theme := baseTheme{NewDefaultTheme()}
+	AssertEqual(t, theme.AttrKey(), attrGroupStyle(theme))
+	AssertEqual(t, theme.Source(), sourceSeparatorStyle(theme))
+	AssertEqual(t, ANSIMod(""), punctuationStyle(theme))
+	AssertEqual(t, theme.LevelInfo(), diffAddedStyle(theme))
+	AssertEqual(t, theme.LevelError(), diffRemovedStyle(theme))
+	AssertEqual(t, theme.AttrValue(), changedStyle(theme, true))
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add(slog.Group("g", "k", "v"))
+	out := string(Render(rec, &HandlerOptions{Theme: theme}))
+	AssertEqual(t, true, strings.Contains(out, styled("g.", theme.AttrKey())+styled("k=", theme.AttrKey())+"v"))
+
+	// Wrapping themes forward the optional styles
+	def := NewDefaultTheme()
+	AssertEqual(t, punctuationStyle(def), punctuationStyle(keyTheme{wrappedTheme{def}, ""}))
+	AssertEqual(t, changedStyle(def, true), valueTheme{wrappedTheme{def}, true}.AttrValue())
+}
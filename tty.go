@@ -0,0 +1,100 @@
+package console
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// IsTerminal reports whether w is a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty(f)
+}
+
+// isTerminal reports whether the writer of o is a terminal.
+func (o *output) isTerminal() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return IsTerminal(o.w)
+}
+
+// ColorsFromEnv reports whether colors should be written to w according
+// to the conventions of the environment variables NO_COLOR, FORCE_COLOR,
+// CLICOLOR_FORCE and CLICOLOR, in that order of precedence:
+//   - a non-empty NO_COLOR disables colors
+//   - FORCE_COLOR or CLICOLOR_FORCE, when non-empty and not "0", enables
+//     colors, even if w is not a terminal
+//   - CLICOLOR set to "0" disables colors
+//
+// Otherwise, colors are written to terminals only.
+func ColorsFromEnv(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	for _, key := range []string{"FORCE_COLOR", "CLICOLOR_FORCE"} {
+		if v := os.Getenv(key); v != "" && v != "0" {
+			return true
+		}
+	}
+	if os.Getenv("CLICOLOR") == "0" {
+		return false
+	}
+	return IsTerminal(w)
+}
+
+// AutoColor returns w if it is a terminal, or a writer stripping the ANSI
+// escape sequences written to w otherwise. It decides on colors for each
+// destination when a handler's output is split, like with io.MultiWriter,
+// so that a terminal gets colors while a piped or file copy stays plain:
+//
+//	out := io.MultiWriter(console.AutoColor(os.Stderr), console.AutoColor(file))
+//	logger := slog.New(console.NewHandler(out, nil))
+func AutoColor(w io.Writer) io.Writer {
+	if IsTerminal(w) {
+		return w
+	}
+	return &plainWriter{w: w}
+}
+
+// plainWriter writes to w without the ANSI escape sequences. It is safe
+// for concurrent use, as it may be shared by several handlers.
+type plainWriter struct {
+	w   io.Writer
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	if bytes.IndexByte(b, '\x1b') < 0 {
+		return p.w.Write(b)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = appendStripANSI(p.buf[:0], b)
+	if _, err := p.w.Write(p.buf); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// appendStripANSI appends b without its ANSI escape sequences to dst.
+func appendStripANSI(dst, b []byte) []byte {
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\x1b')
+		if i < 0 {
+			return append(dst, b...)
+		}
+		dst = append(dst, b[:i]...)
+		b = b[i+1:]
+		if len(b) > 0 && b[0] == '[' {
+			j := 1
+			for j < len(b) && (b[j] < 0x40 || b[j] > 0x7e) {
+				j++
+			}
+			b = b[min(j+1, len(b)):]
+		}
+	}
+	return dst
+}
@@ -0,0 +1,65 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestIsTerminal(t *testing.T) {
+	AssertEqual(t, false, IsTerminal(&bytes.Buffer{}))
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	AssertNoError(t, err)
+	defer f.Close()
+	AssertEqual(t, false, IsTerminal(f))
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	AssertNoError(t, err)
+	defer null.Close()
+	AssertEqual(t, false, IsTerminal(null))
+}
+
+func TestAutoColor(t *testing.T) {
+	plain, colored := bytes.Buffer{}, bytes.Buffer{}
+	out := io.MultiWriter(AutoColor(&plain), &colored)
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.Add("k", "v", "n", 1)
+	h := NewHandler(out, nil)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF msg k=v n=1\n", plain.String())
+	AssertEqual(t, string(Render(rec, nil)), colored.String())
+}
+
+func TestColorsFromEnv(t *testing.T) {
+	for _, tc := range []struct {
+		noColor, forceColor, cliColorForce, cliColor string
+		expected                                     bool
+	}{
+		{"", "", "", "", false},
+		{"", "1", "", "", true},
+		{"", "0", "", "", false},
+		{"", "", "1", "", true},
+		{"1", "1", "", "", false},
+		{"", "", "", "0", false},
+		{"", "1", "", "0", true},
+	} {
+		t.Setenv("NO_COLOR", tc.noColor)
+		t.Setenv("FORCE_COLOR", tc.forceColor)
+		t.Setenv("CLICOLOR_FORCE", tc.cliColorForce)
+		t.Setenv("CLICOLOR", tc.cliColor)
+		AssertEqual(t, tc.expected, ColorsFromEnv(&bytes.Buffer{}))
+
+		h := NewHandler(&bytes.Buffer{}, &HandlerOptions{ColorFromEnv: true})
+		AssertEqual(t, !tc.expected, h.Options().NoColor)
+	}
+	AssertEqual(t, false, NewHandler(&bytes.Buffer{}, nil).Options().NoColor)
+}
+
+func TestAppendStripANSI(t *testing.T) {
+	AssertEqual(t, "ab", string(appendStripANSI(nil, []byte("\x1b[1;31ma\x1b[0mb"))))
+	AssertEqual(t, "a", string(appendStripANSI(nil, []byte("a\x1b[1"))))
+	AssertEqual(t, "a", string(appendStripANSI(nil, []byte("a\x1b"))))
+}
@@ -60,3 +60,7 @@ type writerFunc func([]byte) (int, error)
 func (w writerFunc) Write(b []byte) (int, error) {
 	return w(b)
 }
+
+// nopWriter discards everything written to it, like io.Discard,
+// without letting the handler skip the formatting.
+var nopWriter = writerFunc(func(b []byte) (int, error) { return len(b), nil })
@@ -0,0 +1,73 @@
+package console
+
+import (
+	"runtime"
+	"time"
+)
+
+// processStart is the time the program started, used to compute the
+// elapsed time in verbose mode and the uptime. It holds a monotonic
+// clock reading.
+var processStart = time.Now()
+
+// writeElapsed writes the time elapsed between the start of
+// the program and t, rounded to the millisecond, like "+1.204s".
+func (e *encoder) writeElapsed(buf *buffer, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	e.withColor(buf, e.opts.Theme.Timestamp(), func() {
+		buf.AppendByte('+')
+		buf.AppendDuration(t.Sub(processStart).Round(time.Millisecond))
+	})
+	buf.AppendByte(' ')
+}
+
+// UptimeKey is the key of the attribute added by HandlerOptions.AddUptime.
+const UptimeKey = "uptime"
+
+// writeUptime writes the time elapsed between the start of the uptime
+// and t as a trailing attribute, like " uptime=1h2m3.004s", dimmed.
+func (e *encoder) writeUptime(buf *buffer, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	start := e.opts.UptimeStart
+	if start.IsZero() {
+		start = processStart
+	}
+	buf.AppendString(e.opts.AttrSeparator)
+	e.withColor(buf, e.opts.Theme.Timestamp(), func() {
+		buf.AppendString(UptimeKey)
+		buf.AppendString(e.opts.KeyValueSeparator)
+		buf.AppendDuration(t.Sub(start).Round(time.Millisecond))
+	})
+}
+
+// writeGoroutineID writes the id of the calling goroutine, like "g12".
+func (e *encoder) writeGoroutineID(buf *buffer) {
+	e.withColor(buf, e.opts.Theme.Source(), func() {
+		buf.AppendByte('g')
+		buf.AppendUint(goroutineID())
+	})
+	buf.AppendByte(' ')
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from the
+// header of its stack trace, like "goroutine 12 [running]:".
+func goroutineID() uint64 {
+	var stack [64]byte
+	b := stack[:runtime.Stack(stack[:], false)]
+	const prefix = "goroutine "
+	if len(b) < len(prefix) {
+		return 0
+	}
+	var id uint64
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
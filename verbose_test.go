@@ -0,0 +1,44 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGoroutineID(t *testing.T) {
+	ids := make(chan uint64, 1)
+	go func() { ids <- goroutineID() }()
+	id := goroutineID()
+	AssertEqual(t, true, id > 0)
+	other := <-ids
+	AssertEqual(t, true, other > 0 && other != id)
+}
+
+func TestHandler_Verbose(t *testing.T) {
+	buf := bytes.Buffer{}
+	slog.New(NewHandler(&buf, &HandlerOptions{NoColor: true, Verbose: true})).Info("hello")
+	_, file, _, _ := runtime.Caller(0)
+	expected := `^\S+ \S+ \+\d[\w.]*s INF g\d+ ` + regexp.QuoteMeta(file) + `:\d+ github\.com/phsym/console-slog\.TestHandler_Verbose > hello\n$`
+	if !regexp.MustCompile(expected).MatchString(buf.String()) {
+		t.Errorf("%q does not match %q", buf.String(), expected)
+	}
+}
+
+func TestHandler_AddUptime(t *testing.T) {
+	buf := bytes.Buffer{}
+	start := time.Now()
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true, AddUptime: true, UptimeStart: start})
+	rec := slog.NewRecord(start.Add(90*time.Second+1234*time.Microsecond), slog.LevelInfo, "tick", 0)
+	rec.Add("n", 1)
+	AssertNoError(t, h.Handle(context.Background(), rec))
+	AssertEqual(t, "INF tick n=1 uptime=1m30.001s\n", buf.String())
+
+	buf.Reset()
+	AssertNoError(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "tick", 0)))
+	AssertEqual(t, "INF tick\n", buf.String())
+}
@@ -0,0 +1,88 @@
+package console
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// NewLineWriter creates a LineWriter logging lines at level with handler.
+// The attributes attrs, typically a label of the source of the lines like
+// slog.String("cmd", "make"), are added to each record:
+//
+//	cmd.Stdout = console.NewLineWriter(h, slog.LevelInfo, slog.String("cmd", "make"))
+//	cmd.Stderr = console.NewLineWriter(h, slog.LevelWarn, slog.String("cmd", "make"))
+func NewLineWriter(handler slog.Handler, level slog.Level, attrs ...slog.Attr) *LineWriter {
+	if len(attrs) > 0 {
+		handler = handler.WithAttrs(attrs)
+	}
+	return &LineWriter{handler: handler, level: level}
+}
+
+// Writer returns a writer logging each line written to it as the message
+// of a record at the given level, which allows wiring the output of an
+// exec.Cmd, or of libraries only taking an io.Writer, to the handler.
+// Its Close logs the last line if it doesn't end with a newline.
+func (h *Handler) Writer(level slog.Level) *LineWriter {
+	return NewLineWriter(h, level)
+}
+
+// LineWriter is an io.Writer splitting what is written to it into lines,
+// and logging each of them as the message of a record, so that the output
+// of subprocesses is integrated in the log stream rather than dumped raw.
+// Lines are logged once their newline is written. It's safe for
+// concurrent use.
+type LineWriter struct {
+	handler slog.Handler
+	level   slog.Level
+
+	mu      sync.Mutex
+	pending []byte // Incomplete last line
+}
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(p)
+	for {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			break
+		}
+		line := p[:i]
+		if len(w.pending) > 0 {
+			line = append(w.pending, line...)
+			w.pending = w.pending[:0]
+		}
+		if err := w.log(line); err != nil {
+			return n - len(p) + i + 1, err
+		}
+		p = p[i+1:]
+	}
+	w.pending = append(w.pending, p...)
+	return n, nil
+}
+
+// Close logs the pending incomplete line, if any.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	err := w.log(w.pending)
+	w.pending = w.pending[:0]
+	return err
+}
+
+// log logs line, without its trailing carriage return, if enabled.
+func (w *LineWriter) log(line []byte) error {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, w.level) {
+		return nil
+	}
+	return w.handler.Handle(ctx, slog.NewRecord(time.Now(), w.level, string(line), 0))
+}
@@ -0,0 +1,34 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+func TestHandler_Writer(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true})
+	w := h.Writer(slog.LevelWarn)
+	fmt.Fprint(w, "first line\r\nsec")
+	AssertEqual(t, "WRN first line\n", buf.String())
+	fmt.Fprint(w, "ond line\nlast")
+	AssertEqual(t, "WRN first line\nWRN second line\n", buf.String())
+	AssertNoError(t, w.Close())
+	AssertEqual(t, "WRN first line\nWRN second line\nWRN last\n", buf.String())
+
+	buf.Reset()
+	fmt.Fprintln(h.Writer(slog.LevelDebug), "hidden")
+	AssertEqual(t, "", buf.String())
+}
+
+func TestLineWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	h := NewHandler(&buf, &HandlerOptions{NoColor: true, HideTime: true})
+	w := NewLineWriter(h, slog.LevelInfo, slog.String("cmd", "make"))
+	fmt.Fprint(w, "building\n\ndone")
+	AssertNoError(t, w.Close())
+	AssertNoError(t, w.Close())
+	AssertEqual(t, "INF building cmd=make\nINF  cmd=make\nINF done cmd=make\n", buf.String())
+}